@@ -0,0 +1,408 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// 轮转后文件名中的时间格式，按字典序即按时间排序
+const accessLogStamp = "20060102-150405"
+
+// rotatingFile 按大小和时间轮转的日志文件，轮转出的旧文件可压缩并按数量和天数清理
+type rotatingFile struct {
+	mu        sync.Mutex
+	cleanupMu sync.Mutex // 连续轮转时依次压缩和清理，避免同时处理同一批文件
+	path      string
+	cfg       AccessLogConfig
+	file      *os.File
+	size      int64
+	openedAt  time.Time
+}
+
+func openRotatingFile(cfg AccessLogConfig) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create access log directory: %w", err)
+	}
+	f := &rotatingFile{path: cfg.Path, cfg: cfg}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open access log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat access log: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	// 沿用已有文件时，从其修改时间开始计算轮转周期
+	f.openedAt = time.Now()
+	if info.Size() > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.needsRotation(int64(len(p)), time.Now()) {
+		if err := f.rotate(); err != nil {
+			log.Printf("Failed to rotate access log: %v", err)
+		}
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// 写入后超过大小上限，或已进入新的时间周期
+func (f *rotatingFile) needsRotation(n int64, now time.Time) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.cfg.MaxSizeMB > 0 && f.size+n > int64(f.cfg.MaxSizeMB)<<20 {
+		return true
+	}
+	if f.cfg.RotateHours > 0 {
+		period := time.Duration(f.cfg.RotateHours) * time.Hour
+		return !now.Truncate(period).Equal(f.openedAt.Truncate(period))
+	}
+	return false
+}
+
+// 将当前文件改名为带时间戳的备份，打开新文件，然后在后台压缩和清理旧文件
+func (f *rotatingFile) rotate() error {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	backup := f.path + "." + time.Now().Format(accessLogStamp)
+	for i := 1; fileExists(backup); i++ {
+		backup = fmt.Sprintf("%s.%s-%d", f.path, time.Now().Format(accessLogStamp), i)
+	}
+	if err := os.Rename(f.path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rename access log: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	go f.cleanup(backup)
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// 重新打开日志文件（外部工具移走文件后通过SIGHUP通知）
+func (f *rotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	return f.open()
+}
+
+// 压缩刚轮转出的文件，并按保留数量和天数删除旧文件
+func (f *rotatingFile) cleanup(backup string) {
+	f.cleanupMu.Lock()
+	defer f.cleanupMu.Unlock()
+
+	if f.cfg.Compress {
+		if err := gzipFile(backup); err != nil {
+			log.Printf("Failed to compress %s: %v", backup, err)
+		}
+	}
+
+	matches, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return
+	}
+	// 只处理 rotate 生成的备份（access.log.bak 等其他文件不动），按时间戳和序号排列，最新的在前
+	var backups []string
+	for _, name := range matches {
+		if _, _, ok := f.backupStamp(name); ok {
+			backups = append(backups, name)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		ti, ni, _ := f.backupStamp(backups[i])
+		tj, nj, _ := f.backupStamp(backups[j])
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return ni > nj
+	})
+	cutoff := time.Now().AddDate(0, 0, -f.cfg.MaxAgeDays)
+	for i, name := range backups {
+		expired := f.cfg.MaxAgeDays > 0
+		if expired {
+			info, err := os.Stat(name)
+			expired = err == nil && info.ModTime().Before(cutoff)
+		}
+		if f.cfg.MaxBackups > 0 && i >= f.cfg.MaxBackups || expired {
+			if err := os.Remove(name); err != nil {
+				log.Printf("Failed to remove old access log %s: %v", name, err)
+			}
+		}
+	}
+}
+
+// 解析备份文件名 <path>.<时间戳>[-序号][.gz] 中的时间戳和序号
+func (f *rotatingFile) backupStamp(name string) (time.Time, int, bool) {
+	rest := strings.TrimPrefix(name, f.path+".")
+	if rest == name {
+		return time.Time{}, 0, false
+	}
+	rest = strings.TrimSuffix(rest, ".gz")
+	if len(rest) < len(accessLogStamp) {
+		return time.Time{}, 0, false
+	}
+	t, err := time.ParseInLocation(accessLogStamp, rest[:len(accessLogStamp)], time.Local)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	seq := 0
+	if suffix := rest[len(accessLogStamp):]; suffix != "" {
+		if !strings.HasPrefix(suffix, "-") {
+			return time.Time{}, 0, false
+		}
+		seq, err = strconv.Atoi(suffix[1:])
+		if err != nil || seq < 1 {
+			return time.Time{}, 0, false
+		}
+	}
+	return t, seq, true
+}
+
+// 将文件压缩为 .gz 后删除原文件
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(path)
+	_, err = io.Copy(zw, src)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// accessRecorder 记录响应状态码和字节数
+type accessRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rec *accessRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *accessRecorder) Write(p []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(p)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// accessEntry JSON格式的访问日志
+type accessEntry struct {
+	Time       time.Time `json:"time"`
+	RemoteAddr string    `json:"remote_addr"`
+	Method     string    `json:"method"`
+	URI        string    `json:"uri"`
+	Proto      string    `json:"proto"`
+	Status     int       `json:"status"`
+	Bytes      int64     `json:"bytes"`
+	DurationMS float64   `json:"duration_ms"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// 格式化一条访问日志（含换行）
+func formatAccessLog(format string, e accessEntry) []byte {
+	if format == "json" {
+		data, _ := json.Marshal(e)
+		return append(data, '\n')
+	}
+
+	size := "-"
+	if e.Bytes > 0 {
+		size = strconv.FormatInt(e.Bytes, 10)
+	}
+	line := fmt.Sprintf(`%s - - [%s] "%s %s %s" %d %s`,
+		e.RemoteAddr, e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		clfEscape(e.Method), clfEscape(e.URI), clfEscape(e.Proto), e.Status, size)
+	if format == "combined" {
+		line += fmt.Sprintf(` "%s" "%s"`, clfField(e.Referer), clfField(e.UserAgent))
+	}
+	return []byte(line + "\n")
+}
+
+// 转义引号、反斜杠和控制字符，避免伪造日志行
+func clfEscape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&sb, `\x%02x`, c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func clfField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return clfEscape(s)
+}
+
+// 日志中不记录的凭据：查询参数 token（邀请链接）和私人订阅地址 /feeds/{token}.xml
+const redacted = "REDACTED"
+
+var privateFeedSegment = regexp.MustCompile(`^/feeds/[^/?]+\.xml`)
+
+// 隐去请求地址（或完整的 Referer 地址）中的凭据
+func redactURI(uri string) string {
+	rest, query := uri, ""
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		rest, query = uri[:i], uri[i+1:]
+	}
+	// Referer 为完整地址时只处理其中的路径
+	prefix := ""
+	if i := strings.Index(rest, "://"); i >= 0 {
+		if j := strings.IndexByte(rest[i+3:], '/'); j >= 0 {
+			prefix, rest = rest[:i+3+j], rest[i+3+j:]
+		}
+	}
+	rest = privateFeedSegment.ReplaceAllString(rest, "/feeds/"+redacted+".xml")
+	if query == "" {
+		return prefix + rest
+	}
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		name := param
+		if j := strings.IndexByte(param, '='); j >= 0 {
+			name = param[:j]
+		}
+		if unescaped, err := url.QueryUnescape(name); err == nil && unescaped == "token" {
+			params[i] = name + "=" + redacted
+		}
+	}
+	return prefix + rest + "?" + strings.Join(params, "&")
+}
+
+// 访问日志中间件：请求完成后按配置格式写入日志文件
+func accessLogMiddleware(out io.Writer, format string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &accessRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		line := formatAccessLog(format, accessEntry{
+			Time:       start,
+			RemoteAddr: clientIP(r),
+			Method:     r.Method,
+			URI:        redactURI(r.RequestURI),
+			Proto:      r.Proto,
+			Status:     rec.status,
+			Bytes:      rec.bytes,
+			DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+			Referer:    redactURI(r.Referer()),
+			UserAgent:  r.UserAgent(),
+		})
+		if _, err := out.Write(line); err != nil {
+			log.Printf("Failed to write access log: %v", err)
+		}
+	})
+}
+
+// 按配置包装访问日志，未设置路径时原样返回；收到SIGHUP时重新打开日志文件
+func withAccessLog(next http.Handler) http.Handler {
+	cfg := config.AccessLog
+	if cfg.Path == "" {
+		return next
+	}
+	switch cfg.Format {
+	case "common", "combined", "json":
+	default:
+		log.Fatalf("Unknown access log format %q (use common, combined or json)", cfg.Format)
+	}
+	file, err := openRotatingFile(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open access log: %v", err)
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := file.Reopen(); err != nil {
+				log.Printf("Failed to reopen access log: %v", err)
+				continue
+			}
+			log.Printf("Reopened access log %s", cfg.Path)
+		}
+	}()
+
+	log.Printf("Writing %s access log to %s", cfg.Format, cfg.Path)
+	return accessLogMiddleware(file, cfg.Format, next)
+}
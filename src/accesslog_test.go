@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactURIHidesCredentials(t *testing.T) {
+	cases := map[string]string{
+		"/invite?token=abc123":                                "/invite?token=REDACTED",
+		"/invite?lang=en&token=abc123&x=1":                    "/invite?lang=en&token=REDACTED&x=1",
+		"/feeds/0123456789abcdef0123456789abcdef01234567.xml": "/feeds/REDACTED.xml",
+		"https://blog.example.com/invite?token=abc123":        "https://blog.example.com/invite?token=REDACTED",
+		"/api/blogs?tag=go&page=2":                            "/api/blogs?tag=go&page=2",
+		"/feeds/":                                             "/feeds/",
+		"":                                                    "",
+	}
+	for in, want := range cases {
+		if got := redactURI(in); got != want {
+			t.Errorf("redactURI(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// 在临时目录中打开轮转日志，测试结束时关闭
+func openTestAccessLog(t *testing.T, cfg AccessLogConfig) *rotatingFile {
+	t.Helper()
+	cfg.Path = filepath.Join(t.TempDir(), "access.log")
+	f, err := openRotatingFile(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		f.mu.Lock()
+		if f.file != nil {
+			f.file.Close()
+		}
+		f.mu.Unlock()
+		// 等待后台清理结束
+		f.cleanupMu.Lock()
+		f.cleanupMu.Unlock()
+	})
+	return f
+}
+
+func accessLogBackups(t *testing.T, f *rotatingFile) []string {
+	t.Helper()
+	matches, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range matches {
+		names = append(names, filepath.Base(m))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestAccessLogRotatesBySize(t *testing.T) {
+	f := openTestAccessLog(t, AccessLogConfig{MaxSizeMB: 1})
+	line := []byte(strings.Repeat("x", 600<<10) + "\n")
+	for i := 0; i < 2; i++ {
+		if _, err := f.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if backups := accessLogBackups(t, f); len(backups) != 1 {
+		t.Fatalf("backups after exceeding the size limit: %v", backups)
+	}
+	if info, err := os.Stat(f.path); err != nil || info.Size() != int64(len(line)) {
+		t.Errorf("current log should hold only the last write: %v %v", info, err)
+	}
+}
+
+func TestAccessLogRotatesByTime(t *testing.T) {
+	f := openTestAccessLog(t, AccessLogConfig{RotateHours: 1})
+	if _, err := f.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("same hour\n")); err != nil {
+		t.Fatal(err)
+	}
+	if backups := accessLogBackups(t, f); len(backups) != 0 {
+		t.Fatalf("rotated within the same period: %v", backups)
+	}
+
+	f.mu.Lock()
+	f.openedAt = f.openedAt.Add(-time.Hour)
+	f.mu.Unlock()
+	if _, err := f.Write([]byte("next hour\n")); err != nil {
+		t.Fatal(err)
+	}
+	if backups := accessLogBackups(t, f); len(backups) != 1 {
+		t.Fatalf("backups after the period ended: %v", backups)
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "next hour\n" {
+		t.Errorf("current log = %q", data)
+	}
+}
+
+func TestAccessLogCleanupKeepsUnrelatedFiles(t *testing.T) {
+	f := openTestAccessLog(t, AccessLogConfig{MaxBackups: 2, MaxAgeDays: 7})
+	now := time.Now()
+	stamp := func(d time.Duration) string { return now.Add(-d).Format(accessLogStamp) }
+	files := map[string]time.Duration{
+		"access.log." + stamp(time.Hour) + "-1": time.Hour,
+		"access.log." + stamp(time.Hour):        time.Hour,
+		"access.log." + stamp(2*time.Hour):      2 * time.Hour,
+		"access.log." + stamp(240*time.Hour):    240 * time.Hour,
+		"access.log.bak":                        240 * time.Hour,
+		"access.log.old.gz":                     240 * time.Hour,
+	}
+	dir := filepath.Dir(f.path)
+	for name, age := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("log\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f.cleanup(filepath.Join(dir, "access.log."+stamp(time.Hour)+"-1"))
+
+	want := []string{
+		"access.log." + stamp(time.Hour),
+		"access.log." + stamp(time.Hour) + "-1",
+		"access.log.bak",
+		"access.log.old.gz",
+	}
+	sort.Strings(want)
+	got := accessLogBackups(t, f)
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("files after cleanup:\n got %v\nwant %v", got, want)
+	}
+}
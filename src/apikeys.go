@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKey API访问密钥，只保存密钥的摘要
+type APIKey struct {
+	ID            string    `json:"id"`                        // 密钥ID（公开，用于统计和管理）
+	Name          string    `json:"name"`                      // 用途说明，如脚本名称
+	UserID        int       `json:"user_id,omitempty"`         // 所属用户
+	Hash          string    `json:"hash"`                      // 密钥的SHA-256摘要
+	Admin         bool      `json:"admin,omitempty"`           // 是否可访问管理接口（属于用户的密钥以用户当前的角色为准，见 keyIsAdmin）
+	RatePerMinute int       `json:"rate_per_minute,omitempty"` // 每分钟请求上限（0为使用默认值）
+	DailyQuota    int       `json:"daily_quota,omitempty"`     // 每日请求配额（0为使用默认值）
+	CreatedTime   time.Time `json:"created_at"`                // 创建时间
+}
+
+// 密钥存储文件
+const apiKeyFile = "data/apikeys.json"
+
+// 密钥前缀，便于在日志和代码仓库中识别泄露的密钥
+const apiKeyPrefix = "blog_"
+
+var apiKeysMu sync.Mutex
+
+// 加载全部密钥，文件不存在时返回空列表
+func loadAPIKeys() ([]APIKey, error) {
+	data, err := os.ReadFile(apiKeyFile)
+	if os.IsNotExist(err) {
+		return []APIKey{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API key file: %w", err)
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func saveAPIKeys(keys []APIKey) error {
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal API keys: %w", err)
+	}
+	if err := os.WriteFile(apiKeyFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write API key file: %w", err)
+	}
+	return nil
+}
+
+func hashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// 创建密钥，返回明文密钥（仅此一次可见）
+func createAPIKey(key APIKey) (*APIKey, string, error) {
+	apiKeysMu.Lock()
+	defer apiKeysMu.Unlock()
+
+	keys, err := loadAPIKeys()
+	if err != nil {
+		return nil, "", err
+	}
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	token := apiKeyPrefix + hex.EncodeToString(secret)
+
+	key.ID = strings.ToLower(newULID(time.Now())[16:])
+	key.Hash = hashAPIKey(token)
+	key.CreatedTime = time.Now().UTC()
+	keys = append(keys, key)
+	if err := saveAPIKeys(keys); err != nil {
+		return nil, "", err
+	}
+	return &key, token, nil
+}
+
+// 按明文密钥查找
+func findAPIKey(token string) (*APIKey, error) {
+	keys, err := loadAPIKeys()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashAPIKey(token)
+	for i := range keys {
+		if keys[i].Hash == hash {
+			return &keys[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// 请求中携带的密钥：Authorization: Bearer <key> 或 X-API-Key 头
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+type apiKeyContextKey struct{}
+
+// 当前请求使用的密钥，匿名请求返回nil
+func requestAPIKey(r *http.Request) *APIKey {
+	key, _ := r.Context().Value(apiKeyContextKey{}).(*APIKey)
+	return key
+}
+
+func withAPIKey(r *http.Request, key *APIKey) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key))
+}
+
+// 检查请求是否使用管理员密钥，否则写入错误响应并返回false
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	key := requestAPIKey(r)
+	if key == nil {
+		sendResponse(w, false, "", nil, "API key required", http.StatusUnauthorized)
+		return false
+	}
+	if !keyIsAdmin(key) {
+		sendResponse(w, false, "", nil, "Admin API key required", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// 密钥是否有管理权限：属于用户的密钥跟随用户当前的角色，角色变更后立即生效；
+// 不属于用户（或用户不在用户列表中）的密钥按创建时的设置
+func keyIsAdmin(key *APIKey) bool {
+	if key.UserID == 0 {
+		return key.Admin
+	}
+	users, err := loadUsers()
+	if err != nil {
+		log.Printf("Failed to load users: %v", err)
+		return false
+	}
+	for _, u := range users {
+		if u.ID == key.UserID {
+			return u.Role == "admin"
+		}
+	}
+	return key.Admin
+}
+
+// 管理API密钥：blog apikey create|list|revoke
+func runAPIKey(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: blog apikey create|list|revoke")
+	}
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("apikey create", flag.ExitOnError)
+		name := fs.String("name", "", "what the key is used for")
+		user := fs.Int("user", 0, "owning user ID")
+		admin := fs.Bool("admin", false, "allow access to admin endpoints")
+		rate := fs.Int("rate", 0, "requests per minute (0 uses the configured default)")
+		quota := fs.Int("quota", 0, "requests per day (0 uses the configured default)")
+		fs.Parse(args[1:])
+		if *name == "" {
+			return fmt.Errorf("-name is required")
+		}
+		key, token, err := createAPIKey(APIKey{Name: *name, UserID: *user, Admin: *admin, RatePerMinute: *rate, DailyQuota: *quota})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created API key %s (%s)\n%s\n", key.ID, key.Name, token)
+		fmt.Println("Store this key now; it cannot be shown again.")
+		return nil
+
+	case "list":
+		keys, err := loadAPIKeys()
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			flags := ""
+			if keyIsAdmin(&k) {
+				flags = " admin"
+			}
+			fmt.Printf("%s  %-20s user=%d rate=%d/min quota=%d/day%s\n", k.ID, k.Name, k.UserID, k.RatePerMinute, k.DailyQuota, flags)
+		}
+		return nil
+
+	case "revoke":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: blog apikey revoke <id>")
+		}
+		apiKeysMu.Lock()
+		defer apiKeysMu.Unlock()
+		keys, err := loadAPIKeys()
+		if err != nil {
+			return err
+		}
+		for i, k := range keys {
+			if k.ID == args[1] {
+				keys = append(keys[:i], keys[i+1:]...)
+				fmt.Printf("Revoked API key %s (%s)\n", k.ID, k.Name)
+				return saveAPIKeys(keys)
+			}
+		}
+		return fmt.Errorf("API key %q not found", args[1])
+	}
+	return fmt.Errorf("unknown apikey command %q", args[0])
+}
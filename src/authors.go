@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Author 作者资料
+type Author struct {
+	ID       int    `json:"id"`                 // 作者ID（对应博客的 author_id）
+	Name     string `json:"name"`               // 显示名称
+	Timezone string `json:"timezone,omitempty"` // 显示时区（IANA名称，如 Asia/Shanghai）
+}
+
+// 作者存储目录
+const authorDir = "data/authors"
+
+func init() {
+	if err := os.MkdirAll(authorDir, 0755); err != nil {
+		log.Fatalf("Failed to create author directory: %v", err)
+	}
+}
+
+// Save 保存作者资料
+func (a *Author) Save() error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal author: %w", err)
+	}
+	filename := filepath.Join(authorDir, fmt.Sprintf("%d.json", a.ID))
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write author file: %w", err)
+	}
+	return nil
+}
+
+// LoadAuthor 加载作者资料
+func LoadAuthor(id int) (*Author, error) {
+	data, err := os.ReadFile(filepath.Join(authorDir, fmt.Sprintf("%d.json", id)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read author file: %w", err)
+	}
+	var author Author
+	if err := json.Unmarshal(data, &author); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal author: %w", err)
+	}
+	return &author, nil
+}
+
+var authorIDPath = regexp.MustCompile("^/api/authors/([0-9]+)$")
+
+// 作者资料处理器：GET 获取，PUT 创建或更新
+func authorHandler(w http.ResponseWriter, r *http.Request) {
+	matches := authorIDPath.FindStringSubmatch(r.URL.Path)
+	if matches == nil {
+		sendResponse(w, false, "", nil, "invalid author ID path", http.StatusBadRequest)
+		return
+	}
+	id, _ := strconv.Atoi(matches[1])
+
+	switch r.Method {
+	case http.MethodGet:
+		author, err := LoadAuthor(id)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Author not found", http.StatusNotFound)
+			return
+		}
+		sendResponse(w, true, "Author retrieved successfully", author, "", http.StatusOK)
+
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		var author Author
+		if err := json.Unmarshal(body, &author); err != nil {
+			sendResponse(w, false, "", nil, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		if author.ID != id {
+			sendResponse(w, false, "", nil, "Author ID mismatch", http.StatusBadRequest)
+			return
+		}
+		if author.Timezone != "" {
+			if _, err := time.LoadLocation(author.Timezone); err != nil {
+				sendResponse(w, false, "", nil, "Unknown timezone: "+author.Timezone, http.StatusBadRequest)
+				return
+			}
+		}
+		if err := author.Save(); err != nil {
+			sendResponse(w, false, "", nil, "Failed to save author", http.StatusInternalServerError)
+			return
+		}
+		sendResponse(w, true, "Author saved successfully", author, "", http.StatusOK)
+
+	default:
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,860 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// CSLItem 参考文献条目，字段与 CSL-JSON 一致
+type CSLItem struct {
+	ID             string    `json:"id"`                        // 引用键，正文中以 [@id] 引用
+	Type           string    `json:"type"`                      // 条目类型，如 article-journal、book
+	Title          string    `json:"title"`                     // 标题
+	Author         []CSLName `json:"author,omitempty"`          // 作者
+	Issued         *CSLDate  `json:"issued,omitempty"`          // 发表日期
+	ContainerTitle string    `json:"container-title,omitempty"` // 期刊、会议或文集名称
+	Publisher      string    `json:"publisher,omitempty"`       // 出版者
+	Volume         string    `json:"volume,omitempty"`          // 卷
+	Issue          string    `json:"issue,omitempty"`           // 期
+	Page           string    `json:"page,omitempty"`            // 页码范围
+	DOI            string    `json:"DOI,omitempty"`
+	URL            string    `json:"URL,omitempty"`
+}
+
+// CSLName 作者姓名，机构作者使用 literal
+type CSLName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+// CSLDate 日期，date-parts 形如 [[2020, 5, 1]]
+type CSLDate struct {
+	DateParts [][]json.Number `json:"date-parts,omitempty"`
+	Literal   string          `json:"literal,omitempty"`
+}
+
+// 参考文献库文件
+const bibliographyFile = "data/bibliography.json"
+
+var bibliographyMu sync.Mutex
+
+// 支持的引用样式
+var citationStyles = map[string]bool{"apa": true, "chicago": true, "ieee": true}
+
+// 加载参考文献库，文件不存在时返回空库
+func loadBibliography() (map[string]CSLItem, error) {
+	data, err := os.ReadFile(bibliographyFile)
+	if os.IsNotExist(err) {
+		return map[string]CSLItem{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bibliography: %w", err)
+	}
+	var items []CSLItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bibliography: %w", err)
+	}
+	bib := make(map[string]CSLItem, len(items))
+	for _, item := range items {
+		bib[item.ID] = item
+	}
+	return bib, nil
+}
+
+func saveBibliography(bib map[string]CSLItem) error {
+	items := make([]CSLItem, 0, len(bib))
+	for _, item := range bib {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bibliography: %w", err)
+	}
+	if err := os.WriteFile(bibliographyFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write bibliography: %w", err)
+	}
+	return nil
+}
+
+// 导入 BibTeX 或 CSL-JSON（按内容自动识别），同键条目被覆盖
+func importBibliography(data []byte) ([]CSLItem, error) {
+	var items []CSLItem
+	var err error
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		err = json.Unmarshal(trimmed, &items)
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var item CSLItem
+		err = json.Unmarshal(trimmed, &item)
+		items = []CSLItem{item}
+	default:
+		items, err = parseBibTeX(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bibliography: %w", err)
+	}
+	for _, item := range items {
+		if !citationKey.MatchString(item.ID) {
+			return nil, fmt.Errorf("invalid citation key %q", item.ID)
+		}
+	}
+
+	bibliographyMu.Lock()
+	defer bibliographyMu.Unlock()
+	bib, err := loadBibliography()
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		bib[item.ID] = item
+	}
+	if err := saveBibliography(bib); err != nil {
+		return nil, err
+	}
+	pageCache.Invalidate("bibliography", "index")
+	return items, nil
+}
+
+// BibTeX 条目类型对应的 CSL 类型
+var bibTeXTypes = map[string]string{
+	"article":       "article-journal",
+	"book":          "book",
+	"inproceedings": "paper-conference",
+	"conference":    "paper-conference",
+	"incollection":  "chapter",
+	"inbook":        "chapter",
+	"phdthesis":     "thesis",
+	"mastersthesis": "thesis",
+	"techreport":    "report",
+	"online":        "webpage",
+}
+
+var bibTeXMonths = map[string]string{"jan": "1", "feb": "2", "mar": "3", "apr": "4", "may": "5", "jun": "6", "jul": "7", "aug": "8", "sep": "9", "oct": "10", "nov": "11", "dec": "12"}
+
+// 解析BibTeX，支持 {…}、"…" 和数字/月份缩写形式的字段值，忽略 @comment、@string、@preamble
+func parseBibTeX(src string) ([]CSLItem, error) {
+	var items []CSLItem
+	for i := 0; i < len(src); {
+		at := strings.IndexByte(src[i:], '@')
+		if at < 0 {
+			break
+		}
+		i += at + 1
+		open := strings.IndexAny(src[i:], "{(")
+		if open < 0 {
+			return nil, fmt.Errorf("unterminated entry at offset %d", i)
+		}
+		kind := strings.ToLower(strings.TrimSpace(src[i : i+open]))
+		end, err := matchingBrace(src, i+open)
+		if err != nil {
+			return nil, err
+		}
+		body := src[i+open+1 : end]
+		i = end + 1
+		if kind == "comment" || kind == "string" || kind == "preamble" {
+			continue
+		}
+
+		comma := strings.IndexByte(body, ',')
+		if comma < 0 {
+			return nil, fmt.Errorf("entry @%s has no fields", kind)
+		}
+		fields, err := parseBibTeXFields(body[comma+1:])
+		if err != nil {
+			return nil, fmt.Errorf("entry %s: %w", strings.TrimSpace(body[:comma]), err)
+		}
+		items = append(items, bibTeXToCSL(kind, strings.TrimSpace(body[:comma]), fields))
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no BibTeX entries found")
+	}
+	return items, nil
+}
+
+// 返回与 src[open] 处的 { 或 ( 匹配的位置，其间的花括号需成对出现
+func matchingBrace(src string, open int) (int, error) {
+	depth := 0
+	for i := open + 1; i < len(src); i++ {
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 && src[open] == '{' {
+				return i, nil
+			}
+			depth--
+		case ')':
+			if depth == 0 && src[open] == '(' {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unbalanced braces at offset %d", open)
+}
+
+// 解析 name = value 形式的字段列表
+func parseBibTeXFields(s string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t\r\n,")
+		if s == "" {
+			return fields, nil
+		}
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("expected '=' near %q", excerpt(s, 20))
+		}
+		name := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t\r\n")
+		if s == "" {
+			return nil, fmt.Errorf("missing value for %s", name)
+		}
+
+		var value string
+		switch s[0] {
+		case '{':
+			end, err := matchingBrace(s, 0)
+			if err != nil {
+				return nil, err
+			}
+			value, s = s[1:end], s[end+1:]
+		case '"':
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string for %s", name)
+			}
+			value, s = s[1:end+1], s[end+2:]
+		default:
+			end := strings.IndexAny(s, ",\n")
+			if end < 0 {
+				end = len(s)
+			}
+			value, s = strings.TrimSpace(s[:end]), s[end:]
+			if month, ok := bibTeXMonths[strings.ToLower(value)]; ok {
+				value = month
+			}
+		}
+		fields[name] = cleanBibTeX(value)
+	}
+}
+
+var bibTeXEscapes = strings.NewReplacer(`\&`, "&", `\%`, "%", `\_`, "_", `\$`, "$", `\#`, "#", "---", "—", "--", "–", "{", "", "}", "")
+
+// 去除BibTeX中的保护括号和常见转义
+func cleanBibTeX(s string) string {
+	return strings.Join(strings.Fields(bibTeXEscapes.Replace(s)), " ")
+}
+
+func bibTeXToCSL(kind, key string, f map[string]string) CSLItem {
+	item := CSLItem{
+		ID:        key,
+		Type:      bibTeXTypes[kind],
+		Title:     f["title"],
+		Publisher: f["publisher"],
+		Volume:    f["volume"],
+		Issue:     f["number"],
+		Page:      f["pages"],
+		DOI:       f["doi"],
+		URL:       f["url"],
+	}
+	if item.Type == "" {
+		item.Type = "document"
+		if item.URL != "" {
+			item.Type = "webpage"
+		}
+	}
+	for _, name := range []string{"journal", "journaltitle", "booktitle"} {
+		if f[name] != "" {
+			item.ContainerTitle = f[name]
+			break
+		}
+	}
+	if item.Publisher == "" {
+		item.Publisher = firstNonEmpty(f["institution"], f["school"], f["organization"])
+	}
+	for _, name := range strings.Split(f["author"], " and ") {
+		if name = strings.TrimSpace(name); name != "" {
+			item.Author = append(item.Author, parseBibTeXName(name))
+		}
+	}
+	if year := f["year"]; year != "" {
+		parts := []json.Number{json.Number(year)}
+		if month := f["month"]; month != "" {
+			parts = append(parts, json.Number(month))
+		}
+		item.Issued = &CSLDate{DateParts: [][]json.Number{parts}}
+	} else if date := f["date"]; date != "" {
+		item.Issued = &CSLDate{Literal: date}
+	}
+	return item
+}
+
+// 解析 "Family, Given" 或 "Given Family" 形式的姓名；没有空格的名称视为机构名
+func parseBibTeXName(name string) CSLName {
+	if comma := strings.IndexByte(name, ','); comma >= 0 {
+		return CSLName{Family: strings.TrimSpace(name[:comma]), Given: strings.TrimSpace(name[comma+1:])}
+	}
+	if space := strings.LastIndexByte(name, ' '); space >= 0 {
+		return CSLName{Family: name[space+1:], Given: name[:space]}
+	}
+	return CSLName{Literal: name}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// 引用标记：[@key] 、[@key, p. 12] 或 [@a; @b]
+var (
+	citationMarker = regexp.MustCompile(`\[(@[^\[\]\n]+)\]`)
+	citationKey    = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_:.#$%&+?<>~/-]*$`)
+)
+
+// citation 一处引用中的一个条目
+type citation struct {
+	Key     string
+	Locator string // 页码等定位信息
+}
+
+// 解析引用标记内容，不是合法引用时返回nil
+func parseCitations(inner string) []citation {
+	var cites []citation
+	for _, part := range strings.Split(inner, ";") {
+		part = strings.TrimSpace(part)
+		if !strings.HasPrefix(part, "@") {
+			return nil
+		}
+		key, locator := part[1:], ""
+		if comma := strings.IndexByte(key, ','); comma >= 0 {
+			key, locator = strings.TrimSpace(key[:comma]), strings.TrimSpace(key[comma+1:])
+		}
+		if !citationKey.MatchString(key) {
+			return nil
+		}
+		cites = append(cites, citation{Key: key, Locator: locator})
+	}
+	return cites
+}
+
+// 对文本中行内代码以外的部分执行替换
+func replaceOutsideCode(s string, replace func(string) string) string {
+	parts := strings.Split(s, "`")
+	for i := range parts {
+		// 偶数下标在代码之外；未闭合的反引号之后按普通文本处理
+		if i%2 == 0 || i == len(parts)-1 && len(parts)%2 == 0 {
+			parts[i] = replace(parts[i])
+		}
+	}
+	return strings.Join(parts, "`")
+}
+
+// 正文中引用的全部键（按首次出现顺序，代码块中的内容除外）
+func citedKeys(blocks []Block) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	forEachCitableText(blocks, func(text string) string {
+		replaceOutsideCode(text, func(s string) string {
+			for _, m := range citationMarker.FindAllStringSubmatch(s, -1) {
+				for _, c := range parseCitations(m[1]) {
+					if !seen[c.Key] {
+						seen[c.Key] = true
+						keys = append(keys, c.Key)
+					}
+				}
+			}
+			return s
+		})
+		return text
+	})
+	return keys
+}
+
+// 对可能包含引用的文本字段执行替换（代码、图片和嵌入块除外）
+func forEachCitableText(blocks []Block, replace func(string) string) {
+	for i := range blocks {
+		switch blocks[i].Type {
+		case "paragraph", "heading", "quote", "callout":
+			blocks[i].Text = replace(blocks[i].Text)
+		case "list":
+			items := make([]string, len(blocks[i].Items))
+			for j, item := range blocks[i].Items {
+				items[j] = replace(item)
+			}
+			blocks[i].Items = items
+		}
+	}
+}
+
+// 正文中引用了但参考文献库中不存在的键
+func unknownCitations(b *Blog) ([]string, error) {
+	bib, err := loadBibliography()
+	if err != nil {
+		return nil, err
+	}
+	var unknown []string
+	for _, key := range citedKeys(blogBlocks(b)) {
+		if _, ok := bib[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	return unknown, nil
+}
+
+// 将引用标记替换为格式化的引用，并在末尾追加参考文献列表。
+// 没有引用时原样返回；未知的键保留原始标记。
+func applyCitations(blocks []Block, style string) []Block {
+	keys := citedKeys(blocks)
+	if len(keys) == 0 {
+		return blocks
+	}
+	bib, err := loadBibliography()
+	if err != nil {
+		return blocks
+	}
+	refs := orderReferences(keys, bib, style)
+	number := make(map[string]int)
+	for i, item := range refs {
+		number[item.ID] = i + 1
+	}
+
+	out := append([]Block(nil), blocks...)
+	forEachCitableText(out, func(text string) string {
+		return replaceOutsideCode(text, func(s string) string {
+			return citationMarker.ReplaceAllStringFunc(s, func(marker string) string {
+				cites := parseCitations(marker[1 : len(marker)-1])
+				if cites == nil {
+					return marker
+				}
+				for _, c := range cites {
+					if _, ok := bib[c.Key]; !ok {
+						return marker
+					}
+				}
+				return formatInlineCitation(cites, bib, number, style)
+			})
+		})
+	})
+	if len(refs) == 0 {
+		return out
+	}
+
+	list := Block{Type: "list", Ordered: style == "ieee"}
+	for _, item := range refs {
+		list.Items = append(list.Items, formatReference(item, style))
+	}
+	heading := "References"
+	if strings.HasPrefix(config.Language, "zh") {
+		heading = "参考文献"
+	}
+	return append(out, Block{Type: "heading", Level: 2, Text: heading}, list)
+}
+
+// 参考文献列表的顺序：IEEE 按首次引用顺序编号，其他样式按作者和年份排序
+func orderReferences(keys []string, bib map[string]CSLItem, style string) []CSLItem {
+	var refs []CSLItem
+	for _, key := range keys {
+		if item, ok := bib[key]; ok {
+			refs = append(refs, item)
+		}
+	}
+	if style != "ieee" {
+		sort.SliceStable(refs, func(i, j int) bool {
+			a, b := strings.ToLower(sortName(refs[i])), strings.ToLower(sortName(refs[j]))
+			if a != b {
+				return a < b
+			}
+			return issuedYear(refs[i]) < issuedYear(refs[j])
+		})
+	}
+	return refs
+}
+
+func sortName(item CSLItem) string {
+	if len(item.Author) == 0 {
+		return item.Title
+	}
+	return familyName(item.Author[0])
+}
+
+func familyName(n CSLName) string {
+	if n.Family != "" {
+		return n.Family
+	}
+	return n.Literal
+}
+
+func issuedYear(item CSLItem) string {
+	if item.Issued == nil {
+		return "n.d."
+	}
+	if len(item.Issued.DateParts) > 0 && len(item.Issued.DateParts[0]) > 0 {
+		return item.Issued.DateParts[0][0].String()
+	}
+	if item.Issued.Literal != "" {
+		return strings.SplitN(item.Issued.Literal, "-", 2)[0]
+	}
+	return "n.d."
+}
+
+// 首字母缩写，如 "John Ronald" -> "J. R."
+func initials(given string) string {
+	var parts []string
+	for _, word := range strings.FieldsFunc(given, func(r rune) bool { return r == ' ' || r == '-' }) {
+		r := []rune(word)
+		if len(r) > 0 && unicode.IsLetter(r[0]) {
+			parts = append(parts, string(r[0])+".")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// 行内引用中的作者部分
+func citationAuthors(item CSLItem, and string) string {
+	switch n := len(item.Author); {
+	case n == 0:
+		return "“" + item.Title + "”"
+	case n == 1:
+		return familyName(item.Author[0])
+	case n == 2:
+		return familyName(item.Author[0]) + " " + and + " " + familyName(item.Author[1])
+	default:
+		return familyName(item.Author[0]) + " et al."
+	}
+}
+
+// 格式化一处行内引用
+func formatInlineCitation(cites []citation, bib map[string]CSLItem, number map[string]int, style string) string {
+	parts := make([]string, 0, len(cites))
+	for _, c := range cites {
+		item := bib[c.Key]
+		switch style {
+		case "ieee":
+			part := "[" + strconv.Itoa(number[c.Key])
+			if c.Locator != "" {
+				part += ", " + c.Locator
+			}
+			parts = append(parts, escapeMarkdown(part+"]"))
+		case "chicago":
+			part := citationAuthors(item, "and") + " " + issuedYear(item)
+			if c.Locator != "" {
+				part += ", " + strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(c.Locator, "pp."), "p."))
+			}
+			parts = append(parts, part)
+		default:
+			part := citationAuthors(item, "&") + ", " + issuedYear(item)
+			if c.Locator != "" {
+				part += ", " + c.Locator
+			}
+			parts = append(parts, part)
+		}
+	}
+	if style == "ieee" {
+		return strings.Join(parts, ", ")
+	}
+	return escapeMarkdown("(" + strings.Join(parts, "; ") + ")")
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`)
+
+// 转义Markdown行内语法字符
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// 按样式格式化一条参考文献（Markdown）
+func formatReference(item CSLItem, style string) string {
+	var names []string
+	for i, n := range item.Author {
+		switch {
+		case n.Literal != "" || n.Given == "":
+			names = append(names, familyName(n))
+		case style == "ieee":
+			names = append(names, initials(n.Given)+" "+n.Family)
+		case style == "chicago" && i == 0:
+			names = append(names, n.Family+", "+n.Given)
+		case style == "chicago":
+			names = append(names, n.Given+" "+n.Family)
+		default:
+			names = append(names, n.Family+", "+initials(n.Given))
+		}
+	}
+	title := escapeMarkdown(item.Title)
+	container := escapeMarkdown(item.ContainerTitle)
+	year := issuedYear(item)
+	isPart := item.ContainerTitle != ""
+	link := ""
+	if item.DOI != "" {
+		link = "https://doi.org/" + item.DOI
+	} else if item.URL != "" {
+		link = item.URL
+	}
+
+	var sb strings.Builder
+	switch style {
+	case "ieee":
+		if len(names) > 0 {
+			sb.WriteString(joinNames(names, ", ", " and ", ", and ") + ", ")
+		}
+		if isPart {
+			sb.WriteString("“" + title + ",” *" + container + "*")
+		} else {
+			sb.WriteString("*" + title + "*")
+			if item.Publisher != "" {
+				sb.WriteString(". " + escapeMarkdown(item.Publisher))
+			}
+		}
+		if item.Volume != "" {
+			sb.WriteString(", vol. " + item.Volume)
+		}
+		if item.Issue != "" {
+			sb.WriteString(", no. " + item.Issue)
+		}
+		if item.Page != "" {
+			sb.WriteString(", pp. " + item.Page)
+		}
+		sb.WriteString(", " + year)
+		if item.DOI != "" {
+			sb.WriteString(", doi: " + item.DOI)
+		} else if item.URL != "" {
+			sb.WriteString(". [Online]. Available: " + item.URL)
+		}
+		sb.WriteString(".")
+
+	case "chicago":
+		if len(names) > 0 {
+			sb.WriteString(strings.TrimSuffix(joinNames(names, ", ", ", and ", ", and "), ".") + ". ")
+		}
+		sb.WriteString(year + ". ")
+		if isPart {
+			sb.WriteString("“" + title + ".” *" + container + "*")
+			if item.Volume != "" {
+				sb.WriteString(" " + item.Volume)
+			}
+			if item.Issue != "" {
+				sb.WriteString(" (" + item.Issue + ")")
+			}
+			if item.Page != "" {
+				sb.WriteString(": " + item.Page)
+			}
+			sb.WriteString(".")
+		} else {
+			sb.WriteString("*" + title + "*.")
+			if item.Publisher != "" {
+				sb.WriteString(" " + escapeMarkdown(item.Publisher) + ".")
+			}
+		}
+		if link != "" {
+			sb.WriteString(" " + link + ".")
+		}
+
+	default:
+		if len(names) > 0 {
+			sb.WriteString(joinNames(names, ", ", ", & ", ", & ") + " ")
+		}
+		sb.WriteString("(" + year + "). ")
+		if isPart {
+			sb.WriteString(title + ". *" + container)
+			if item.Volume != "" {
+				sb.WriteString(", " + item.Volume + "*")
+				if item.Issue != "" {
+					sb.WriteString("(" + item.Issue + ")")
+				}
+			} else {
+				sb.WriteString("*")
+			}
+			if item.Page != "" {
+				sb.WriteString(", " + item.Page)
+			}
+			sb.WriteString(".")
+		} else {
+			sb.WriteString("*" + title + "*.")
+			if item.Publisher != "" {
+				sb.WriteString(" " + escapeMarkdown(item.Publisher) + ".")
+			}
+		}
+		if link != "" {
+			sb.WriteString(" " + link)
+		}
+	}
+	return sb.String()
+}
+
+// 连接作者姓名：两人时使用 pair，三人及以上最后一位使用 last
+func joinNames(names []string, sep, pair, last string) string {
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + pair + names[1]
+	}
+	return strings.Join(names[:len(names)-1], sep) + last + names[len(names)-1]
+}
+
+// 站点使用的引用样式
+func citationStyle() string {
+	if citationStyles[config.CitationStyle] {
+		return config.CitationStyle
+	}
+	return "apa"
+}
+
+// 参考文献处理器：GET /api/bibliography 列出条目，POST 导入 BibTeX 或 CSL-JSON，DELETE /api/bibliography/{key} 删除条目
+func bibliographyHandler(w http.ResponseWriter, r *http.Request) {
+	key := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/bibliography"), "/")
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		bib, err := loadBibliography()
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to load bibliography", http.StatusInternalServerError)
+			return
+		}
+		items := make([]CSLItem, 0, len(bib))
+		for _, item := range bib {
+			items = append(items, item)
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+		sendResponse(w, true, "Bibliography retrieved successfully", items, "", http.StatusOK)
+
+	case key == "" && r.Method == http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		items, err := importBibliography(body)
+		if err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendResponse(w, true, fmt.Sprintf("Imported %d entries", len(items)), items, "", http.StatusOK)
+
+	case key != "" && r.Method == http.MethodDelete:
+		bibliographyMu.Lock()
+		defer bibliographyMu.Unlock()
+		bib, err := loadBibliography()
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to load bibliography", http.StatusInternalServerError)
+			return
+		}
+		if _, ok := bib[key]; !ok {
+			sendResponse(w, false, "", nil, "Entry not found", http.StatusNotFound)
+			return
+		}
+		delete(bib, key)
+		if err := saveBibliography(bib); err != nil {
+			sendResponse(w, false, "", nil, "Failed to save bibliography", http.StatusInternalServerError)
+			return
+		}
+		pageCache.Invalidate("bibliography", "index")
+		sendResponse(w, true, "Entry deleted successfully", nil, "", http.StatusOK)
+
+	default:
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// referenceList 博客的参考文献列表
+type referenceList struct {
+	Style       string   `json:"style"`
+	References  []string `json:"references"` // Markdown格式
+	UnknownKeys []string `json:"unknown_keys,omitempty"`
+}
+
+var blogReferencesPath = regexp.MustCompile("^/api/blogs/([0-9A-Za-z_-]+)/references$")
+
+// 博客参考文献处理器：GET /api/blogs/{id}/references?style=apa|chicago|ieee
+func referencesHandler(w http.ResponseWriter, r *http.Request) {
+	matches := blogReferencesPath.FindStringSubmatch(r.URL.Path)
+	id, err := parseBlogID(matches[1])
+	if err != nil {
+		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+	blog, err := LoadBlog(id)
+	if err != nil || !canReadBlog(r, blog) {
+		sendResponse(w, false, "", nil, "Blog not found", http.StatusNotFound)
+		return
+	}
+	style := r.URL.Query().Get("style")
+	if style == "" {
+		style = citationStyle()
+	}
+	if !citationStyles[style] {
+		sendResponse(w, false, "", nil, "style must be apa, chicago or ieee", http.StatusBadRequest)
+		return
+	}
+	bib, err := loadBibliography()
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to load bibliography", http.StatusInternalServerError)
+		return
+	}
+
+	result := referenceList{Style: style, References: []string{}}
+	keys := citedKeys(blogBlocks(blog))
+	for _, item := range orderReferences(keys, bib, style) {
+		result.References = append(result.References, formatReference(item, style))
+	}
+	for _, key := range keys {
+		if _, ok := bib[key]; !ok {
+			result.UnknownKeys = append(result.UnknownKeys, key)
+		}
+	}
+	sendResponse(w, true, "References retrieved successfully", result, "", http.StatusOK)
+}
+
+// 导入参考文献：blog bib import <file.bib|file.json>，blog bib list
+func runBib(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: blog bib import|list")
+	}
+	switch args[0] {
+	case "import":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: blog bib import <file>")
+		}
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		items, err := importBibliography(data)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d entries\n", len(items))
+		return nil
+
+	case "list":
+		bib, err := loadBibliography()
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(bib))
+		for key := range bib {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("%-24s %s\n", key, formatReference(bib[key], citationStyle()))
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown bib command %q", args[0])
+}
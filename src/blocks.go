@@ -0,0 +1,333 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Block 结构化内容块，作为 Content 之外可选的内容格式
+type Block struct {
+	Type     string   `json:"type"`               // 块类型，见 blockTypes
+	Text     string   `json:"text,omitempty"`     // 文本（可包含行内Markdown）
+	Level    int      `json:"level,omitempty"`    // 标题级别（1-6）
+	Language string   `json:"language,omitempty"` // 代码语言
+	URL      string   `json:"url,omitempty"`      // 图片或嵌入内容地址
+	Alt      string   `json:"alt,omitempty"`      // 图片替代文本
+	Caption  string   `json:"caption,omitempty"`  // 图片说明
+	Style    string   `json:"style,omitempty"`    // 提示框样式
+	Items    []string `json:"items,omitempty"`    // 列表项
+	Ordered  bool     `json:"ordered,omitempty"`  // 是否为有序列表
+}
+
+// 支持的块类型
+var blockTypes = map[string]bool{
+	"paragraph": true,
+	"heading":   true,
+	"code":      true,
+	"image":     true,
+	"callout":   true,
+	"embed":     true,
+	"list":      true,
+	"quote":     true,
+	"divider":   true,
+}
+
+// 提示框样式（与 GitHub 的 [!NOTE] 语法对应）
+var calloutStyles = map[string]bool{"note": true, "tip": true, "important": true, "warning": true, "caution": true}
+
+var (
+	mdImageBlock = regexp.MustCompile(`^!\[([^\]]*)\]\(\s*(\S+?)(?:\s+"([^"]*)")?\s*\)$`)
+	mdEmbedBlock = regexp.MustCompile(`^@\[embed\]\(\s*(\S+?)\s*\)$`)
+	mdCallout    = regexp.MustCompile(`^\[!(\w+)\]\s*`)
+)
+
+// 校验内容块，返回第一个错误
+func validateBlocks(blocks []Block) error {
+	for i, b := range blocks {
+		if err := validateBlock(b); err != nil {
+			return fmt.Errorf("block %d (%s): %w", i, b.Type, err)
+		}
+	}
+	return nil
+}
+
+func validateBlock(b Block) error {
+	if !blockTypes[b.Type] {
+		return fmt.Errorf("unknown block type")
+	}
+
+	switch b.Type {
+	case "paragraph", "quote":
+		if strings.TrimSpace(b.Text) == "" {
+			return fmt.Errorf("text is required")
+		}
+	case "heading":
+		if strings.TrimSpace(b.Text) == "" || strings.Contains(b.Text, "\n") {
+			return fmt.Errorf("heading text must be a single non-empty line")
+		}
+		if b.Level < 1 || b.Level > 6 {
+			return fmt.Errorf("level must be between 1 and 6")
+		}
+	case "code":
+		if b.Text == "" {
+			return fmt.Errorf("text is required")
+		}
+		if strings.Contains(b.Text, "```") {
+			return fmt.Errorf("code must not contain a fence (```)")
+		}
+	case "image", "embed":
+		if !isWebURL(b.URL) && !(b.Type == "image" && strings.HasPrefix(b.URL, "/")) {
+			return fmt.Errorf("url must be an http(s) URL")
+		}
+		if strings.ContainsAny(b.URL, " ()\"") {
+			return fmt.Errorf("url contains invalid characters")
+		}
+	case "callout":
+		if !calloutStyles[b.Style] {
+			return fmt.Errorf("style must be one of note, tip, important, warning, caution")
+		}
+		if strings.TrimSpace(b.Text) == "" {
+			return fmt.Errorf("text is required")
+		}
+	case "list":
+		if len(b.Items) == 0 {
+			return fmt.Errorf("items are required")
+		}
+		for _, item := range b.Items {
+			if strings.TrimSpace(item) == "" || strings.Contains(item, "\n") {
+				return fmt.Errorf("list items must be single non-empty lines")
+			}
+		}
+	}
+	return nil
+}
+
+func isWebURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// 将Markdown转换为内容块
+func markdownToBlocks(src string) []Block {
+	blocks := []Block{}
+	for _, md := range parseMarkdown(src) {
+		switch md.Kind {
+		case "heading":
+			blocks = append(blocks, Block{Type: "heading", Level: md.Level, Text: md.Text})
+		case "paragraph":
+			if m := mdImageBlock.FindStringSubmatch(md.Text); m != nil {
+				blocks = append(blocks, Block{Type: "image", Alt: m[1], URL: m[2], Caption: m[3]})
+			} else if m := mdEmbedBlock.FindStringSubmatch(md.Text); m != nil {
+				blocks = append(blocks, Block{Type: "embed", URL: m[1]})
+			} else {
+				blocks = append(blocks, Block{Type: "paragraph", Text: md.Text})
+			}
+		case "code":
+			blocks = append(blocks, Block{Type: "code", Language: md.Lang, Text: md.Text})
+		case "list":
+			blocks = append(blocks, Block{Type: "list", Ordered: md.Ordered, Items: md.Items})
+		case "quote":
+			text := strings.TrimSpace(mdBlocksToMarkdown(md.Children))
+			if m := mdCallout.FindStringSubmatch(text); m != nil && calloutStyles[strings.ToLower(m[1])] {
+				blocks = append(blocks, Block{Type: "callout", Style: strings.ToLower(m[1]), Text: strings.TrimSpace(text[len(m[0]):])})
+			} else {
+				blocks = append(blocks, Block{Type: "quote", Text: text})
+			}
+		case "hr":
+			blocks = append(blocks, Block{Type: "divider"})
+		}
+	}
+	return blocks
+}
+
+// 将解析后的Markdown元素还原为Markdown文本（用于引用块内容）
+func mdBlocksToMarkdown(mds []mdBlock) string {
+	var blocks []Block
+	for _, md := range mds {
+		switch md.Kind {
+		case "heading":
+			blocks = append(blocks, Block{Type: "heading", Level: md.Level, Text: md.Text})
+		case "paragraph":
+			blocks = append(blocks, Block{Type: "paragraph", Text: md.Text})
+		case "code":
+			blocks = append(blocks, Block{Type: "code", Language: md.Lang, Text: md.Text})
+		case "list":
+			blocks = append(blocks, Block{Type: "list", Ordered: md.Ordered, Items: md.Items})
+		case "quote":
+			blocks = append(blocks, Block{Type: "quote", Text: mdBlocksToMarkdown(md.Children)})
+		case "hr":
+			blocks = append(blocks, Block{Type: "divider"})
+		}
+	}
+	return blocksToMarkdown(blocks)
+}
+
+// 将内容块转换为Markdown
+func blocksToMarkdown(blocks []Block) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		switch b.Type {
+		case "paragraph":
+			parts = append(parts, b.Text)
+		case "heading":
+			parts = append(parts, strings.Repeat("#", b.Level)+" "+b.Text)
+		case "code":
+			parts = append(parts, "```"+b.Language+"\n"+b.Text+"\n```")
+		case "image":
+			img := "![" + b.Alt + "](" + b.URL
+			if b.Caption != "" {
+				img += ` "` + strings.ReplaceAll(b.Caption, `"`, "'") + `"`
+			}
+			parts = append(parts, img+")")
+		case "embed":
+			parts = append(parts, "@[embed]("+b.URL+")")
+		case "callout":
+			parts = append(parts, quoteMarkdown("[!"+strings.ToUpper(b.Style)+"]\n"+b.Text))
+		case "quote":
+			parts = append(parts, quoteMarkdown(b.Text))
+		case "list":
+			items := make([]string, len(b.Items))
+			for i, item := range b.Items {
+				if b.Ordered {
+					items[i] = strconv.Itoa(i+1) + ". " + item
+				} else {
+					items[i] = "- " + item
+				}
+			}
+			parts = append(parts, strings.Join(items, "\n"))
+		case "divider":
+			parts = append(parts, "---")
+		}
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// 给每行加上引用前缀
+func quoteMarkdown(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// 将内容块渲染为HTML
+func blocksToHTML(blocks []Block) string {
+	var sb strings.Builder
+	for _, b := range blocks {
+		switch b.Type {
+		case "paragraph":
+			sb.WriteString("<p>" + strings.ReplaceAll(renderInline(b.Text), "\n", "<br>\n") + "</p>\n")
+		case "heading":
+			tag := "h" + strconv.Itoa(b.Level)
+			sb.WriteString("<" + tag + ">" + renderInline(b.Text) + "</" + tag + ">\n")
+		case "code":
+			renderBlocksHTML(&sb, []mdBlock{{Kind: "code", Lang: b.Language, Text: b.Text}})
+		case "image":
+			sb.WriteString(`<figure><img src="` + html.EscapeString(safeURL(b.URL)) + `" alt="` + html.EscapeString(b.Alt) + `">`)
+			if b.Caption != "" {
+				sb.WriteString("<figcaption>" + html.EscapeString(b.Caption) + "</figcaption>")
+			}
+			sb.WriteString("</figure>\n")
+		case "embed":
+			url := html.EscapeString(safeURL(b.URL))
+			sb.WriteString(`<div class="embed"><a href="` + url + `">` + url + "</a></div>\n")
+		case "callout":
+			sb.WriteString(`<div class="callout callout-` + html.EscapeString(b.Style) + `">` + renderMarkdown(b.Text) + "</div>\n")
+		case "quote":
+			sb.WriteString("<blockquote>\n" + renderMarkdown(b.Text) + "</blockquote>\n")
+		case "list":
+			renderBlocksHTML(&sb, []mdBlock{{Kind: "list", Ordered: b.Ordered, Items: b.Items}})
+		case "divider":
+			sb.WriteString("<hr>\n")
+		}
+	}
+	return sb.String()
+}
+
+// 博客的内容块：有内容块时直接使用，否则由Markdown生成
+func blogBlocks(b *Blog) []Block {
+	if len(b.Blocks) > 0 {
+		return b.Blocks
+	}
+	return markdownToBlocks(b.Content)
+}
+
+// 渲染博客正文，引用标记替换为格式化的引用并附参考文献列表
+func renderBlogHTML(b *Blog) string {
+	return blocksToHTML(applyCitations(blogBlocks(b), citationStyle()))
+}
+
+// 将内容块渲染为纯文本
+func blocksToText(blocks []Block) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		switch b.Type {
+		case "paragraph":
+			parts = append(parts, plainInline(b.Text))
+		case "heading":
+			text := plainInline(b.Text)
+			underline := "-"
+			if b.Level == 1 {
+				underline = "="
+			}
+			parts = append(parts, text+"\n"+strings.Repeat(underline, textWidth(text)))
+		case "image":
+			text := b.Alt
+			if b.Caption != "" {
+				text = b.Caption
+			}
+			parts = append(parts, "["+text+"] ("+b.URL+")")
+		case "embed":
+			parts = append(parts, b.URL)
+		case "callout":
+			parts = append(parts, strings.ToUpper(b.Style)+": "+strings.TrimRight(renderMarkdownText(b.Text), "\n"))
+		default:
+			parts = append(parts, strings.TrimRight(renderMarkdownText(blocksToMarkdown([]Block{b})), "\n"))
+		}
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// 渲染博客正文的纯文本形式
+func renderBlogText(b *Blog) string {
+	return blocksToText(applyCitations(blogBlocks(b), citationStyle()))
+}
+
+// 内容块与Markdown互转：POST /api/blocks/from-markdown 和 /api/blocks/to-markdown
+func convertBlocksHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/api/blocks/from-markdown":
+		sendResponse(w, true, "Converted successfully", markdownToBlocks(string(body)), "", http.StatusOK)
+
+	case "/api/blocks/to-markdown":
+		var blocks []Block
+		if err := json.Unmarshal(body, &blocks); err != nil {
+			sendResponse(w, false, "", nil, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		if err := validateBlocks(blocks); err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendResponse(w, true, "Converted successfully", blocksToMarkdown(blocks), "", http.StatusOK)
+
+	default:
+		sendResponse(w, false, "", nil, "Not found", http.StatusNotFound)
+	}
+}
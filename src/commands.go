@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command 命令行子命令
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+// 子命令列表，不带参数运行时启动API服务器
+var commands = map[string]command{
+	"tui":                {"browse and edit posts in the terminal", runTUI},
+	"migrate-timestamps": {"convert stored timestamps to UTC", runMigrateTimestamps},
+	"import-disqus":      {"import comments from a Disqus XML export", runImportDisqus},
+	"apikey":             {"create, list or revoke API keys", runAPIKey},
+	"sign":               {"sign published posts that lack a valid signature", runSign},
+	"verify":             {"verify post signatures", runVerify},
+	"seed":               {"generate realistic posts for development", runSeed},
+	"dump":               {"copy the data directory for local development (-sanitize removes private data)", runDump},
+	"deploy":             {"build the static site into a new release (deploy rollback|list)", runDeploy},
+	"bib":                {"import BibTeX or CSL-JSON into the bibliography (bib list)", runBib},
+	"planet":             {"subscribe to external feeds and fetch them (planet add|list|remove|fetch)", runPlanet},
+	"promote":            {"export posts with their media and authors, or apply a bundle from another instance (promote push)", runPromote},
+	"users":              {"import users and authors from CSV (users list)", runUsers},
+	"xliff":              {"export a blog for translation or import a translated XLIFF file", runXLIFF},
+}
+
+// 执行子命令
+func runCommand(name string, args []string) error {
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return nil
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		printUsage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+	return cmd.run(args)
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: blog [command] [flags]")
+	fmt.Fprintln(os.Stderr, "\nWithout a command, starts the API server.\n\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].usage)
+	}
+}
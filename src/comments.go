@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Comment 博客评论
+type Comment struct {
+	ID          string    `json:"id"`                   // 评论ID（ULID）
+	BlogID      BlogID    `json:"blog_id"`              // 所属博客
+	ParentID    string    `json:"parent_id,omitempty"`  // 回复的评论ID
+	AuthorName  string    `json:"author_name"`          // 评论者名称
+	EmailHash   string    `json:"email_hash,omitempty"` // 邮箱的SHA-256摘要，不保存原始邮箱
+	Content     string    `json:"content"`              // 内容（纯文本/Markdown）
+	CreatedTime time.Time `json:"created_at"`           // 发表时间
+	Source      string    `json:"source,omitempty"`     // 导入来源，如 disqus
+	SourceID    string    `json:"source_id,omitempty"`  // 在来源系统中的ID，用于去重
+}
+
+// 评论存储目录，每篇博客一个文件
+const commentDir = "data/comments"
+
+func init() {
+	if err := os.MkdirAll(commentDir, 0755); err != nil {
+		log.Fatalf("Failed to create comment directory: %v", err)
+	}
+}
+
+// LoadComments 加载博客的全部评论（按时间排序），没有评论时返回空列表
+func LoadComments(id BlogID) ([]Comment, error) {
+	data, err := os.ReadFile(filepath.Join(commentDir, string(id)+".json"))
+	if os.IsNotExist(err) {
+		return []Comment{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read comment file: %w", err)
+	}
+	var comments []Comment
+	if err := json.Unmarshal(data, &comments); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal comments: %w", err)
+	}
+	return comments, nil
+}
+
+// 保存博客的全部评论
+func saveComments(id BlogID, comments []Comment) error {
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].CreatedTime.Before(comments[j].CreatedTime)
+	})
+	data, err := json.MarshalIndent(comments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal comments: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(commentDir, string(id)+".json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write comment file: %w", err)
+	}
+	return nil
+}
+
+// 计算邮箱摘要（去除首尾空格并转为小写后做SHA-256）
+func hashEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:])
+}
+
+var blogCommentsPath = regexp.MustCompile("^/api/blogs/([0-9A-Za-z_-]+)/comments$")
+
+// 评论列表处理器：GET /api/blogs/{id}/comments
+func commentsHandler(w http.ResponseWriter, r *http.Request) {
+	matches := blogCommentsPath.FindStringSubmatch(r.URL.Path)
+	if matches == nil {
+		sendResponse(w, false, "", nil, "invalid comments path", http.StatusBadRequest)
+		return
+	}
+	id, err := parseBlogID(matches[1])
+	if err != nil {
+		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if blog, err := LoadBlog(id); err != nil || !canReadBlog(r, blog) {
+		sendResponse(w, false, "", nil, "Blog not found", http.StatusNotFound)
+		return
+	}
+
+	comments, err := LoadComments(id)
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to load comments", http.StatusInternalServerError)
+		return
+	}
+	sendResponse(w, true, "Comments retrieved successfully", comments, "", http.StatusOK)
+}
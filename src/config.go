@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// 数据根目录与配置文件路径
+const (
+	dataDir    = "data"
+	configFile = "data/config.json"
+)
+
+// Config 站点配置（从 data/config.json 读取，缺省字段使用默认值）
+type Config struct {
+	Addr          string            `json:"addr"`           // 监听地址
+	SiteTitle     string            `json:"site_title"`     // 站点标题
+	BaseURL       string            `json:"base_url"`       // 站点对外地址，用于生成订阅等处的绝对链接
+	Theme         string            `json:"theme"`          // 默认主题
+	Timezone      string            `json:"timezone"`       // 站点时区（IANA名称），用于归档和日期显示
+	Language      string            `json:"language"`       // 站点语言（BCP 47），即原文语言
+	CitationStyle string            `json:"citation_style"` // 引用样式：apa、chicago 或 ieee
+	IDScheme      string            `json:"id_scheme"`      // 新博客的ID方案：int（递增整数）或 ulid
+	RenderCache   RenderCacheConfig `json:"render_cache"`   // 页面渲染缓存
+	Unfurl        UnfurlConfig      `json:"unfurl"`         // 链接预览抓取
+	Deploy        DeployConfig      `json:"deploy"`         // 静态站点发布
+	IndexNow      IndexNowConfig    `json:"indexnow"`       // 搜索引擎变更通知
+	Usage         UsageConfig       `json:"usage"`          // API使用量统计、限流与配额
+	Mail          MailConfig        `json:"mail"`           // 邮件发送
+	Invitations   InvitationConfig  `json:"invitations"`    // 用户邀请
+	AccessLog     AccessLogConfig   `json:"access_log"`     // 访问日志文件
+	Planet        PlanetConfig      `json:"planet"`         // 外部订阅聚合
+}
+
+// RenderCacheConfig 页面渲染缓存配置
+type RenderCacheConfig struct {
+	Disabled     bool   `json:"disabled"`      // 是否关闭缓存
+	MaxMemoryMB  int    `json:"max_memory_mb"` // 内存占用上限（MB）
+	FreshSeconds int    `json:"fresh_seconds"` // 渲染结果保持新鲜的时长
+	StaleSeconds int    `json:"stale_seconds"` // 过期后仍可返回旧内容的时长
+	SpillDir     string `json:"spill_dir"`     // 内存不足时溢出到磁盘的目录（为空则直接淘汰）
+	MaxDiskMB    int    `json:"max_disk_mb"`   // 磁盘溢出上限（MB）
+}
+
+// UnfurlConfig 链接预览抓取配置
+type UnfurlConfig struct {
+	TimeoutSeconds int   `json:"timeout_seconds"` // 单次抓取超时
+	MaxBytes       int64 `json:"max_bytes"`       // 最多读取的响应字节数
+	RefreshHours   int   `json:"refresh_hours"`   // 预览过期后重新抓取的间隔
+	CacheEntries   int   `json:"cache_entries"`   // 内存中缓存的预览数量上限
+	AllowPrivate   bool  `json:"allow_private"`   // 允许访问内网地址（仅用于本地开发）
+}
+
+// DeployConfig 静态站点发布配置
+type DeployConfig struct {
+	Dir  string `json:"dir"`  // 发布根目录
+	Keep int    `json:"keep"` // 保留的版本数
+}
+
+// IndexNowConfig IndexNow 通知配置，未设置密钥或端点时不提交
+type IndexNowConfig struct {
+	Key          string   `json:"key"`           // 站点密钥，通过 /{key}.txt 对外公开
+	Endpoints    []string `json:"endpoints"`     // 提交地址，如 https://api.indexnow.org/indexnow
+	BatchSeconds int      `json:"batch_seconds"` // 合并提交的等待时长
+	MaxRetries   int      `json:"max_retries"`   // 失败后的最大重试次数
+}
+
+// UsageConfig API使用量统计、限流与配额配置（0表示不限制）
+type UsageConfig struct {
+	RatePerMinute           int `json:"rate_per_minute"`             // 每个密钥每分钟请求上限
+	AnonymousRatePerMinute  int `json:"anonymous_rate_per_minute"`   // 未携带密钥的请求按IP限流
+	InvalidKeyRatePerMinute int `json:"invalid_key_rate_per_minute"` // 使用无效密钥的请求按IP限流
+	DailyQuota              int `json:"daily_quota"`                 // 每个密钥每日请求配额
+	RetentionDays           int `json:"retention_days"`              // 统计数据保留天数
+}
+
+// MailConfig 邮件发送配置
+type MailConfig struct {
+	Driver   string `json:"driver"`    // log（写入 data/outbox，用于开发）或 smtp
+	From     string `json:"from"`      // 发件人
+	SMTPAddr string `json:"smtp_addr"` // SMTP服务器地址，如 smtp.example.com:587
+	Username string `json:"username"`  // SMTP认证用户名（为空时不认证）
+	Password string `json:"password"`  // SMTP认证密码
+}
+
+// InvitationConfig 用户邀请配置
+type InvitationConfig struct {
+	ExpiryHours int `json:"expiry_hours"` // 邀请链接有效期
+}
+
+// AccessLogConfig 访问日志配置，未设置路径时不记录
+type AccessLogConfig struct {
+	Path        string `json:"path"`         // 日志文件路径，如 logs/access.log
+	Format      string `json:"format"`       // common、combined 或 json
+	MaxSizeMB   int    `json:"max_size_mb"`  // 超过该大小时轮转（0为不按大小轮转）
+	RotateHours int    `json:"rotate_hours"` // 每隔多少小时轮转（0为不按时间轮转）
+	MaxBackups  int    `json:"max_backups"`  // 保留的轮转文件数（0为不限）
+	MaxAgeDays  int    `json:"max_age_days"` // 轮转文件保留天数（0为不限）
+	Compress    bool   `json:"compress"`     // 使用gzip压缩轮转出的文件
+}
+
+// PlanetConfig 外部订阅聚合配置
+type PlanetConfig struct {
+	IntervalMinutes int   `json:"interval_minutes"` // 定时抓取间隔（0为只手动抓取）
+	TimeoutSeconds  int   `json:"timeout_seconds"`  // 单次抓取超时
+	MaxBytes        int64 `json:"max_bytes"`        // 最多读取的订阅字节数
+	MaxEntries      int   `json:"max_entries"`      // 聚合流保留的条目数
+	AllowPrivate    bool  `json:"allow_private"`    // 允许访问内网地址（仅用于本地开发）
+}
+
+// 当前生效的配置
+var config = defaultConfig()
+
+func defaultConfig() Config {
+	return Config{
+		Addr:          ":8080",
+		SiteTitle:     "Blog",
+		BaseURL:       "http://localhost:8080",
+		Theme:         "default",
+		Timezone:      "UTC",
+		Language:      "zh-CN",
+		CitationStyle: "apa",
+		IDScheme:      "int",
+		RenderCache: RenderCacheConfig{
+			MaxMemoryMB:  64,
+			FreshSeconds: 300,
+			StaleSeconds: 3600,
+			MaxDiskMB:    512,
+		},
+		Unfurl: UnfurlConfig{
+			TimeoutSeconds: 10,
+			MaxBytes:       1 << 20,
+			RefreshHours:   24 * 7,
+			CacheEntries:   1000,
+		},
+		Deploy: DeployConfig{
+			Dir:  "public",
+			Keep: 5,
+		},
+		IndexNow: IndexNowConfig{
+			BatchSeconds: 10,
+			MaxRetries:   3,
+		},
+		Usage: UsageConfig{
+			RatePerMinute:           600,
+			AnonymousRatePerMinute:  120,
+			InvalidKeyRatePerMinute: 10,
+			RetentionDays:           90,
+		},
+		Mail: MailConfig{
+			Driver: "log",
+			From:   "Blog <noreply@localhost>",
+		},
+		Invitations: InvitationConfig{
+			ExpiryHours: 24 * 7,
+		},
+		AccessLog: AccessLogConfig{
+			Format:      "combined",
+			MaxSizeMB:   100,
+			RotateHours: 24,
+			MaxBackups:  14,
+			MaxAgeDays:  30,
+			Compress:    true,
+		},
+		Planet: PlanetConfig{
+			IntervalMinutes: 60,
+			TimeoutSeconds:  15,
+			MaxBytes:        4 << 20,
+			MaxEntries:      1000,
+		},
+	}
+}
+
+// 加载配置文件，文件不存在时保留默认配置
+func loadConfig(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg := defaultConfig()
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", filepath.Base(path), err)
+	}
+	config = cfg
+	return nil
+}
@@ -0,0 +1,467 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 静态站点发布目录结构：
+//
+//	<dir>/releases/<版本>/  每次构建的完整站点
+//	<dir>/current          指向当前版本的符号链接，Web服务器以它为根目录
+const releasesDir = "releases"
+
+// sitemap 站点地图
+type sitemap struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+const sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// 发布静态站点：blog deploy [-dir d] [-keep n]，blog deploy rollback [release]，blog deploy list
+func runDeploy(args []string) error {
+	sub := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		sub, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("deploy", flag.ExitOnError)
+	dir := fs.String("dir", config.Deploy.Dir, "deploy root containing releases and the current symlink")
+	keep := fs.Int("keep", config.Deploy.Keep, "number of releases to keep")
+	fs.Parse(args)
+
+	switch sub {
+	case "":
+		return deploy(*dir, *keep)
+	case "rollback":
+		return rollback(*dir, fs.Arg(0))
+	case "list":
+		return listReleases(*dir)
+	}
+	return fmt.Errorf("usage: blog deploy [-dir d] [-keep n] | deploy rollback [release] | deploy list")
+}
+
+// 构建新版本、校验后切换 current，并清理旧版本
+func deploy(dir string, keep int) error {
+	if keep < 1 {
+		return fmt.Errorf("keep must be at least 1")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, releasesDir), 0755); err != nil {
+		return fmt.Errorf("failed to create releases directory: %w", err)
+	}
+
+	name := time.Now().UTC().Format("20060102T150405Z")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(dir, releasesDir, name)); os.IsNotExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s-%d", time.Now().UTC().Format("20060102T150405Z"), i)
+	}
+	root := filepath.Join(dir, releasesDir, name)
+
+	fmt.Printf("Building release %s\n", name)
+	pages, err := buildSite(root)
+	if err != nil {
+		os.RemoveAll(root)
+		return fmt.Errorf("build failed: %w", err)
+	}
+
+	if problems := checkLinks(root); len(problems) > 0 {
+		for _, p := range problems {
+			fmt.Println("  " + p)
+		}
+		os.RemoveAll(root)
+		return fmt.Errorf("link check failed with %d problem(s), release discarded", len(problems))
+	}
+	if err := validateSitemap(root); err != nil {
+		os.RemoveAll(root)
+		return fmt.Errorf("sitemap validation failed, release discarded: %w", err)
+	}
+
+	if err := switchCurrent(dir, name); err != nil {
+		return err
+	}
+	fmt.Printf("Deployed %s (%d pages)\n", name, pages)
+	return pruneReleases(dir, keep)
+}
+
+// 渲染全部页面到 root 目录，返回生成的页面数
+func buildSite(root string) (int, error) {
+	theme := siteTheme()
+	loc := siteLocation()
+	var urls []sitemapURL
+
+	// route 为转义后的地址，文件名使用解码后的路径（与静态服务器和 checkLinks 的解析方式一致）
+	write := func(route string, body []byte, lastMod time.Time) error {
+		decoded, err := url.PathUnescape(route)
+		if err != nil {
+			return fmt.Errorf("invalid route %s: %w", route, err)
+		}
+		file := filepath.Join(root, filepath.FromSlash(path.Clean("/"+decoded)))
+		if rel, err := filepath.Rel(root, file); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("route %s is outside the release directory", route)
+		}
+		if strings.HasSuffix(route, "/") {
+			file = filepath.Join(file, "index.html")
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, body, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", route, err)
+		}
+		if strings.HasSuffix(route, "/") {
+			entry := sitemapURL{Loc: absoluteURL(route)}
+			if !lastMod.IsZero() {
+				entry.LastMod = lastMod.UTC().Format(time.RFC3339)
+			}
+			urls = append(urls, entry)
+		}
+		return nil
+	}
+
+	blogs, err := publishedBlogs("")
+	if err != nil {
+		return 0, err
+	}
+	var latest time.Time
+	if len(blogs) > 0 {
+		latest = blogs[0].UpdatedTime
+	}
+
+	body, _, err := renderIndexPage(theme, loc)
+	if err != nil {
+		return 0, err
+	}
+	if err := write("/", body, latest); err != nil {
+		return 0, err
+	}
+
+	tags := make(map[string]bool)
+	months := make(map[[2]int]bool)
+	for _, b := range blogs {
+		body, _, err := renderPostPage(theme, b, loc)
+		if err != nil {
+			return 0, err
+		}
+		if err := write("/blogs/"+string(b.ID)+"/", body, b.UpdatedTime); err != nil {
+			return 0, err
+		}
+		for _, t := range b.Tags {
+			// 校验规则加入前保存的标签可能无法安全地用作目录名，需先修改
+			if err := validateTags([]string{t}); err != nil {
+				return 0, fmt.Errorf("blog %s: %w", b.ID, err)
+			}
+			tags[t] = true
+		}
+		t := b.CreatedTime.In(loc)
+		months[[2]int{t.Year(), int(t.Month())}] = true
+	}
+
+	for tag := range tags {
+		body, _, err := renderTagPage(theme, tag, loc)
+		if err != nil {
+			return 0, err
+		}
+		if err := write(tagURL(tag)+"/", body, time.Time{}); err != nil {
+			return 0, err
+		}
+	}
+
+	body, _, err = renderArchivePage(theme, 0, 0, loc)
+	if err != nil {
+		return 0, err
+	}
+	if err := write("/archive/", body, latest); err != nil {
+		return 0, err
+	}
+	for m := range months {
+		body, _, err := renderArchivePage(theme, m[0], m[1], loc)
+		if err != nil {
+			return 0, err
+		}
+		if err := write(fmt.Sprintf("/archive/%d/%02d/", m[0], m[1]), body, time.Time{}); err != nil {
+			return 0, err
+		}
+	}
+
+	staticPages, err := ListPages()
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range staticPages {
+		body, _, err := renderStaticPage(theme, p)
+		if err != nil {
+			return 0, err
+		}
+		if err := write("/"+p.Path+"/", body, p.UpdatedTime); err != nil {
+			return 0, err
+		}
+	}
+
+	feed, err := renderFeed(config.SiteTitle, absoluteURL("/"), config.SiteTitle, blogs)
+	if err != nil {
+		return 0, err
+	}
+	if err := write("/feed.xml", feed, time.Time{}); err != nil {
+		return 0, err
+	}
+
+	// IndexNow 密钥文件
+	if key := config.IndexNow.Key; key != "" {
+		if err := write("/"+key+".txt", []byte(key), time.Time{}); err != nil {
+			return 0, err
+		}
+	}
+
+	// 页面中 /media/ 下的图片和附件
+	if err := copyMedia(filepath.Join(root, "media")); err != nil {
+		return 0, err
+	}
+
+	sort.Slice(urls, func(i, j int) bool { return urls[i].Loc < urls[j].Loc })
+	data, err := xml.MarshalIndent(sitemap{XMLNS: sitemapNS, URLs: urls}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := write("/sitemap.xml", append([]byte(xml.Header), data...), time.Time{}); err != nil {
+		return 0, err
+	}
+	return len(urls), nil
+}
+
+// 复制媒体目录到 dst
+func copyMedia(dst string) error {
+	return filepath.Walk(mediaDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(mediaDir, file)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read media %s: %w", rel, err)
+		}
+		out := filepath.Join(dst, rel)
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(out, data, 0644); err != nil {
+			return fmt.Errorf("failed to write media %s: %w", rel, err)
+		}
+		return nil
+	})
+}
+
+var htmlLinkAttr = regexp.MustCompile(`(?i)\s(?:href|src)="([^"]*)"`)
+
+// 检查所有HTML页面中的站内链接，返回无法解析到文件的链接
+func checkLinks(root string) []string {
+	var problems []string
+	filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			problems = append(problems, err.Error())
+			return nil
+		}
+		if info.IsDir() || !strings.HasSuffix(file, ".html") {
+			return nil
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			problems = append(problems, err.Error())
+			return nil
+		}
+		rel, _ := filepath.Rel(root, file)
+		for _, m := range htmlLinkAttr.FindAllStringSubmatch(string(data), -1) {
+			link := html.UnescapeString(m[1])
+			if !strings.HasPrefix(link, "/") || strings.HasPrefix(link, "//") {
+				continue // 外部链接和页内锚点不检查
+			}
+			if !releaseHasRoute(root, link) {
+				problems = append(problems, fmt.Sprintf("%s: broken link %s", filepath.ToSlash(rel), link))
+			}
+		}
+		return nil
+	})
+	return problems
+}
+
+// 站内链接是否能对应到发布目录中的文件（目录链接对应其 index.html）
+func releaseHasRoute(root, link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	clean := path.Clean("/" + u.Path)
+	file := filepath.Join(root, filepath.FromSlash(clean))
+	if info, err := os.Stat(file); err == nil && !info.IsDir() {
+		return true
+	}
+	_, err = os.Stat(filepath.Join(file, "index.html"))
+	return err == nil
+}
+
+// 校验站点地图：格式正确、地址为站点绝对地址且不重复、都对应已生成的页面
+func validateSitemap(root string) error {
+	data, err := os.ReadFile(filepath.Join(root, "sitemap.xml"))
+	if err != nil {
+		return err
+	}
+	if len(data) > 50<<20 {
+		return fmt.Errorf("sitemap exceeds 50MB")
+	}
+	var sm sitemap
+	if err := xml.Unmarshal(data, &sm); err != nil {
+		return fmt.Errorf("invalid XML: %w", err)
+	}
+	if sm.XMLName.Space != sitemapNS {
+		return fmt.Errorf("unexpected namespace %q", sm.XMLName.Space)
+	}
+	if len(sm.URLs) == 0 || len(sm.URLs) > 50000 {
+		return fmt.Errorf("sitemap must contain 1 to 50000 URLs, got %d", len(sm.URLs))
+	}
+
+	base := strings.TrimRight(config.BaseURL, "/")
+	seen := make(map[string]bool)
+	for _, u := range sm.URLs {
+		if !strings.HasPrefix(u.Loc, base+"/") {
+			return fmt.Errorf("URL %s is not under %s", u.Loc, base)
+		}
+		if seen[u.Loc] {
+			return fmt.Errorf("duplicate URL %s", u.Loc)
+		}
+		seen[u.Loc] = true
+		if u.LastMod != "" {
+			if _, err := time.Parse(time.RFC3339, u.LastMod); err != nil {
+				return fmt.Errorf("invalid lastmod %q for %s", u.LastMod, u.Loc)
+			}
+		}
+		if !releaseHasRoute(root, strings.TrimPrefix(u.Loc, base)) {
+			return fmt.Errorf("URL %s has no generated page", u.Loc)
+		}
+	}
+	return nil
+}
+
+// 原子切换 current：先创建临时符号链接，再重命名覆盖
+func switchCurrent(dir, release string) error {
+	if _, err := os.Stat(filepath.Join(dir, releasesDir, release)); err != nil {
+		return fmt.Errorf("release %s not found", release)
+	}
+	tmp := filepath.Join(dir, ".current-"+release)
+	os.Remove(tmp)
+	if err := os.Symlink(filepath.Join(releasesDir, release), tmp); err != nil {
+		return fmt.Errorf("failed to create symlink: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, "current")); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to switch current release: %w", err)
+	}
+	return nil
+}
+
+// 当前版本名，未部署过时返回空字符串
+func currentRelease(dir string) string {
+	target, err := os.Readlink(filepath.Join(dir, "current"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
+
+// 全部版本，按时间从旧到新排列
+func releases(dir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, releasesDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read releases: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// 只保留最新的 keep 个版本，当前版本始终保留
+func pruneReleases(dir string, keep int) error {
+	names, err := releases(dir)
+	if err != nil {
+		return err
+	}
+	current := currentRelease(dir)
+	for i := 0; i < len(names)-keep; i++ {
+		if names[i] == current {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, releasesDir, names[i])); err != nil {
+			return fmt.Errorf("failed to remove release %s: %w", names[i], err)
+		}
+		fmt.Printf("Removed old release %s\n", names[i])
+	}
+	return nil
+}
+
+// 回滚到指定版本，未指定时回滚到当前版本的上一个版本
+func rollback(dir, target string) error {
+	names, err := releases(dir)
+	if err != nil {
+		return err
+	}
+	current := currentRelease(dir)
+	if target == "" {
+		for i, name := range names {
+			if name == current && i > 0 {
+				target = names[i-1]
+			}
+		}
+		if target == "" {
+			return fmt.Errorf("no earlier release to roll back to")
+		}
+	}
+	if err := switchCurrent(dir, target); err != nil {
+		return err
+	}
+	fmt.Printf("Rolled back from %s to %s\n", current, target)
+	return nil
+}
+
+func listReleases(dir string) error {
+	names, err := releases(dir)
+	if err != nil {
+		return err
+	}
+	current := currentRelease(dir)
+	for _, name := range names {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// disqusExport Disqus XML导出文件
+type disqusExport struct {
+	Threads []disqusThread `xml:"thread"`
+	Posts   []disqusPost   `xml:"post"`
+}
+
+type disqusThread struct {
+	DsqID      string `xml:"http://disqus.com/disqus-internals id,attr"`
+	Identifier string `xml:"id"`
+	Link       string `xml:"link"`
+	Title      string `xml:"title"`
+}
+
+type disqusPost struct {
+	DsqID     string `xml:"http://disqus.com/disqus-internals id,attr"`
+	Message   string `xml:"message"`
+	CreatedAt string `xml:"createdAt"`
+	IsDeleted bool   `xml:"isDeleted"`
+	IsSpam    bool   `xml:"isSpam"`
+	Author    struct {
+		Name  string `xml:"name"`
+		Email string `xml:"email"`
+	} `xml:"author"`
+	Thread struct {
+		DsqID string `xml:"http://disqus.com/disqus-internals id,attr"`
+	} `xml:"thread"`
+	Parent struct {
+		DsqID string `xml:"http://disqus.com/disqus-internals id,attr"`
+	} `xml:"parent"`
+}
+
+// disqusReport 导入结果报告
+type disqusReport struct {
+	Imported         int               `json:"imported"`
+	Duplicates       int               `json:"duplicates"`
+	SkippedSpam      int               `json:"skipped_spam"`
+	SkippedDeleted   int               `json:"skipped_deleted"`
+	MatchedThreads   int               `json:"matched_threads"`
+	UnmatchedThreads []unmatchedThread `json:"unmatched_threads"`
+	Matches          map[string]BlogID `json:"matches"` // 线程链接 -> 博客ID
+}
+
+type unmatchedThread struct {
+	Link     string `json:"link"`
+	Title    string `json:"title"`
+	Comments int    `json:"comments"`
+}
+
+// 导入 Disqus 评论：blog import-disqus [-dry-run] [-report file] export.xml
+func runImportDisqus(args []string) error {
+	fs := flag.NewFlagSet("import-disqus", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "match threads and report without writing comments")
+	reportFile := fs.String("report", "", "write a JSON report to this file")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: blog import-disqus [-dry-run] [-report file] export.xml")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var export disqusExport
+	if err := xml.NewDecoder(f).Decode(&export); err != nil {
+		return fmt.Errorf("failed to parse Disqus export: %w", err)
+	}
+
+	blogs, err := ListBlogs()
+	if err != nil {
+		return err
+	}
+	report, err := importDisqus(&export, blogs, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d comment(s), %d duplicate(s), skipped %d spam and %d deleted\n",
+		report.Imported, report.Duplicates, report.SkippedSpam, report.SkippedDeleted)
+	fmt.Printf("Matched %d thread(s), %d unmatched\n", report.MatchedThreads, len(report.UnmatchedThreads))
+	for _, t := range report.UnmatchedThreads {
+		fmt.Printf("  unmatched: %s (%q, %d comment(s))\n", t.Link, t.Title, t.Comments)
+	}
+
+	if *reportFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*reportFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	return nil
+}
+
+// 将导出数据中的评论导入匹配到的博客，已导入过的评论（按 dsq:id）会被跳过
+func importDisqus(export *disqusExport, blogs []*Blog, dryRun bool) (*disqusReport, error) {
+	report := &disqusReport{UnmatchedThreads: []unmatchedThread{}, Matches: map[string]BlogID{}}
+
+	// 线程 -> 博客
+	threadBlog := make(map[string]BlogID)
+	threadByID := make(map[string]disqusThread)
+	for _, t := range export.Threads {
+		threadByID[t.DsqID] = t
+		if id, ok := matchDisqusThread(t, blogs); ok {
+			threadBlog[t.DsqID] = id
+			report.Matches[t.Link] = id
+		}
+	}
+
+	// 统计未匹配线程的评论数
+	unmatched := make(map[string]int)
+	for _, p := range export.Posts {
+		if _, ok := threadBlog[p.Thread.DsqID]; !ok {
+			unmatched[p.Thread.DsqID]++
+		}
+	}
+	for dsqID, count := range unmatched {
+		t := threadByID[dsqID]
+		report.UnmatchedThreads = append(report.UnmatchedThreads, unmatchedThread{Link: t.Link, Title: t.Title, Comments: count})
+	}
+	sort.Slice(report.UnmatchedThreads, func(i, j int) bool {
+		return report.UnmatchedThreads[i].Link < report.UnmatchedThreads[j].Link
+	})
+
+	postByID := make(map[string]disqusPost)
+	for _, p := range export.Posts {
+		postByID[p.DsqID] = p
+	}
+
+	// 父评论先于回复导入
+	posts := disqusPostOrder(export.Posts, postByID)
+
+	existing := make(map[BlogID][]Comment)
+	commentID := make(map[string]string) // dsq:id -> 评论ID
+	touched := make(map[BlogID]bool)
+
+	for _, p := range posts {
+		blogID, ok := threadBlog[p.Thread.DsqID]
+		if !ok {
+			continue
+		}
+		if p.IsSpam {
+			report.SkippedSpam++
+			continue
+		}
+		if p.IsDeleted {
+			report.SkippedDeleted++
+			continue
+		}
+
+		if _, loaded := existing[blogID]; !loaded {
+			comments, err := LoadComments(blogID)
+			if err != nil {
+				return nil, err
+			}
+			existing[blogID] = comments
+			for _, c := range comments {
+				if c.Source == "disqus" {
+					commentID[c.SourceID] = c.ID
+				}
+			}
+		}
+		if _, dup := commentID[p.DsqID]; dup {
+			report.Duplicates++
+			continue
+		}
+
+		created, err := time.Parse(time.RFC3339, strings.TrimSpace(p.CreatedAt))
+		if err != nil {
+			return nil, fmt.Errorf("invalid createdAt %q for post %s", p.CreatedAt, p.DsqID)
+		}
+
+		// 父评论被跳过（垃圾/已删除）时挂到最近的已导入祖先上
+		parentID := ""
+		for parent, seen := p.Parent.DsqID, 0; parent != "" && seen < len(postByID); seen++ {
+			if id, ok := commentID[parent]; ok {
+				parentID = id
+				break
+			}
+			parent = postByID[parent].Parent.DsqID
+		}
+
+		name := strings.TrimSpace(p.Author.Name)
+		if name == "" {
+			name = "Anonymous"
+		}
+		c := Comment{
+			ID:          newULID(created),
+			BlogID:      blogID,
+			ParentID:    parentID,
+			AuthorName:  name,
+			EmailHash:   hashEmail(p.Author.Email),
+			Content:     disqusMessageToText(p.Message),
+			CreatedTime: created.UTC(),
+			Source:      "disqus",
+			SourceID:    p.DsqID,
+		}
+		existing[blogID] = append(existing[blogID], c)
+		commentID[p.DsqID] = c.ID
+		touched[blogID] = true
+		report.Imported++
+	}
+
+	report.MatchedThreads = len(threadBlog)
+	if dryRun {
+		return report, nil
+	}
+	for blogID := range touched {
+		if err := saveComments(blogID, existing[blogID]); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// 导入顺序：按时间排列，但沿 Parent 链接保证父评论总在回复之前
+// （回复的时间可能与父评论相同、更早或使用不同的时区偏移）
+func disqusPostOrder(posts []disqusPost, postByID map[string]disqusPost) []disqusPost {
+	byTime := append([]disqusPost(nil), posts...)
+	sort.SliceStable(byTime, func(i, j int) bool {
+		ti, erri := time.Parse(time.RFC3339, strings.TrimSpace(byTime[i].CreatedAt))
+		tj, errj := time.Parse(time.RFC3339, strings.TrimSpace(byTime[j].CreatedAt))
+		if erri != nil || errj != nil {
+			return byTime[i].CreatedAt < byTime[j].CreatedAt
+		}
+		return ti.Before(tj)
+	})
+
+	ordered := make([]disqusPost, 0, len(posts))
+	visited := make(map[string]bool)
+	var visit func(p disqusPost)
+	visit = func(p disqusPost) {
+		if p.DsqID != "" && visited[p.DsqID] {
+			return
+		}
+		// 先标记，避免循环引用时无限递归
+		visited[p.DsqID] = true
+		if parent, ok := postByID[p.Parent.DsqID]; ok && p.Parent.DsqID != "" {
+			visit(parent)
+		}
+		ordered = append(ordered, p)
+	}
+	for _, p := range byTime {
+		visit(p)
+	}
+	return ordered
+}
+
+// 按ID或slug将 Disqus 线程匹配到博客：只有本站（config.BaseURL 的主机）的 /blogs/{id} 链接
+// 和线程标识才按ID匹配，其他链接（如旧站点的 /page/2）只按最后一段路径的slug匹配
+func matchDisqusThread(t disqusThread, blogs []*Blog) (BlogID, bool) {
+	var ids, slugs []string
+	if u, err := url.Parse(strings.TrimSpace(t.Link)); err == nil {
+		path := strings.Trim(u.Path, "/")
+		if m := blogPagePath.FindStringSubmatch("/" + path); m != nil && isSiteHost(u.Host) {
+			ids = append(ids, m[1])
+		}
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			path = path[i+1:]
+		}
+		path = strings.TrimSuffix(strings.TrimSuffix(path, ".html"), ".htm")
+		if path != "" {
+			slugs = append(slugs, path)
+		}
+	}
+	if id := strings.TrimSpace(t.Identifier); id != "" {
+		ids = append(ids, id)
+		slugs = append(slugs, id)
+	}
+
+	for _, id := range ids {
+		for _, b := range blogs {
+			if string(b.ID) == id {
+				return b.ID, true
+			}
+		}
+	}
+	for _, candidate := range slugs {
+		if slug := slugify(candidate); slug != "" {
+			for _, b := range blogs {
+				if slugify(b.Title) == slug {
+					return b.ID, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// 链接的主机是否为本站（config.BaseURL 的主机）
+func isSiteHost(host string) bool {
+	base, err := url.Parse(config.BaseURL)
+	return err == nil && base.Host != "" && strings.EqualFold(host, base.Host)
+}
+
+// 生成slug：小写字母和数字（含中文等文字）保留，其余连续字符替换为连字符
+func slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
+
+var (
+	htmlBreak     = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlParagraph = regexp.MustCompile(`(?i)</p>\s*`)
+	htmlLink      = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	htmlTag       = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+)
+
+// 将 Disqus 评论的HTML内容转换为纯文本，链接保留为Markdown格式
+func disqusMessageToText(message string) string {
+	text := htmlBreak.ReplaceAllString(message, "\n")
+	text = htmlParagraph.ReplaceAllString(text, "\n\n")
+	text = htmlLink.ReplaceAllString(text, "[$2]($1)")
+	text = htmlTag.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	return strings.TrimSpace(blankLines.ReplaceAllString(text, "\n\n"))
+}
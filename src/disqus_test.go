@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+const testDisqusExport = `<?xml version="1.0" encoding="utf-8"?>
+<disqus xmlns="http://disqus.com" xmlns:dsq="http://disqus.com/disqus-internals">
+<thread dsq:id="t1"><id>1</id><link>https://www.example.com/blogs/1</link><title>Launch</title></thread>
+<post dsq:id="reply-same-time"><message>Same second</message><createdAt>2024-01-01T01:00:00Z</createdAt>
+  <author><name>Bob</name></author><thread dsq:id="t1"/><parent dsq:id="parent"/></post>
+<post dsq:id="reply-offset"><message>Later, but sorts first as a string</message><createdAt>2024-01-01T03:00:00Z</createdAt>
+  <author><name>Carol</name></author><thread dsq:id="t1"/><parent dsq:id="parent"/></post>
+<post dsq:id="nested"><message>Reply to a reply, dated before its parent</message><createdAt>2024-01-01T00:30:00Z</createdAt>
+  <author><name>Dan</name></author><thread dsq:id="t1"/><parent dsq:id="reply-offset"/></post>
+<post dsq:id="parent"><message>First!</message><createdAt>2024-01-01T09:00:00+08:00</createdAt>
+  <author><name>Ann</name></author><thread dsq:id="t1"/></post>
+</disqus>`
+
+func TestDisqusImportKeepsRepliesUnderParents(t *testing.T) {
+	useTestSite(t)
+	prevBaseURL := config.BaseURL
+	t.Cleanup(func() { config.BaseURL = prevBaseURL })
+	config.BaseURL = "https://www.example.com"
+	saveTestBlog(t, &Blog{ID: "1", Title: "Launch", Content: "hello", IsPublished: true})
+	blogs, err := ListBlogs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var export disqusExport
+	if err := xml.Unmarshal([]byte(testDisqusExport), &export); err != nil {
+		t.Fatal(err)
+	}
+	report, err := importDisqus(&export, blogs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Imported != 4 {
+		t.Fatalf("imported %d comment(s), want 4", report.Imported)
+	}
+
+	comments, err := LoadComments("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]string)
+	for _, c := range comments {
+		ids[c.SourceID] = c.ID
+	}
+	wantParent := map[string]string{"parent": "", "reply-same-time": "parent", "reply-offset": "parent", "nested": "reply-offset"}
+	for _, c := range comments {
+		if want := ids[wantParent[c.SourceID]]; c.ParentID != want {
+			t.Errorf("comment %s has parent %q, want %q", c.SourceID, c.ParentID, want)
+		}
+	}
+
+	// 重新导入：全部视为重复，不改变已有的层级
+	report, err = importDisqus(&export, blogs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Imported != 0 || report.Duplicates != 4 {
+		t.Errorf("reimport: %+v", report)
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	mrand "math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// scrambler 将文本中的字母和数字替换为随机字符，保留空白、标点、Markdown语法、链接协议和UTF-8字节长度
+type scrambler struct {
+	salt []byte
+}
+
+// 与原字符UTF-8长度相同的替换字符
+const latinScramble = "àáâãäåçèéêëìíîïñòóôõöùúûü"
+
+var urlScheme = regexp.MustCompile(`https?://`)
+
+// 同一次导出中相同的文本得到相同的结果；不同导出使用不同的盐，无法反推原文
+func (s *scrambler) text(text string) string {
+	if text == "" {
+		return ""
+	}
+	sum := sha256.Sum256(append(append([]byte{}, s.salt...), text...))
+	rng := mrand.New(mrand.NewSource(int64(binary.BigEndian.Uint64(sum[:8]))))
+	latin := []rune(latinScramble)
+
+	var sb strings.Builder
+	sb.Grow(len(text))
+	last := 0
+	scrambleRange := func(part string) {
+		for _, r := range part {
+			switch {
+			case r >= 'a' && r <= 'z':
+				r = rune('a' + rng.Intn(26))
+			case r >= 'A' && r <= 'Z':
+				r = rune('A' + rng.Intn(26))
+			case r >= '0' && r <= '9':
+				r = rune('0' + rng.Intn(10))
+			case unicode.IsLetter(r) && utf8.RuneLen(r) == 3:
+				// 汉字、假名、谚文等统一替换为汉字
+				r = rune(0x4E00 + rng.Intn(0x9FA5-0x4E00))
+			case unicode.IsLetter(r) && utf8.RuneLen(r) == 2:
+				r = latin[rng.Intn(len(latin))]
+			}
+			sb.WriteRune(r)
+		}
+	}
+	// 保留链接协议，打乱后的链接仍是合法的URL
+	for _, loc := range urlScheme.FindAllStringIndex(text, -1) {
+		scrambleRange(text[last:loc[0]])
+		sb.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	scrambleRange(text[last:])
+	return sb.String()
+}
+
+// 打乱未发布博客的内容；已发布的博客只去掉签名（导入的站点会用自己的密钥重新签名）
+func (s *scrambler) blog(b *Blog, published bool) {
+	b.Signature = nil
+	if published {
+		return
+	}
+	b.Title = s.text(b.Title)
+	b.Content = s.text(b.Content)
+	b.LinkURL = s.text(b.LinkURL)
+	for i := range b.Tags {
+		b.Tags[i] = s.text(b.Tags[i])
+	}
+	if p := b.LinkPreview; p != nil {
+		p.URL, p.Title, p.Description, p.Image, p.SiteName = s.text(p.URL), s.text(p.Title), s.text(p.Description), s.text(p.Image), s.text(p.SiteName)
+	}
+	for i := range b.Blocks {
+		block := &b.Blocks[i]
+		block.Text, block.Alt, block.Caption = s.text(block.Text), s.text(block.Alt), s.text(block.Caption)
+		if !strings.HasPrefix(block.URL, "/media/") {
+			block.URL = s.text(block.URL)
+		}
+		for j := range block.Items {
+			block.Items[j] = s.text(block.Items[j])
+		}
+	}
+	if t := b.Translation; t != nil {
+		for i := range t.Segments {
+			t.Segments[i].Source, t.Segments[i].Target = s.text(t.Segments[i].Source), s.text(t.Segments[i].Target)
+		}
+	}
+}
+
+// 邮箱替换为加盐摘要，保持唯一性
+func (s *scrambler) email(email string) string {
+	sum := sha256.Sum256(append(append([]byte{}, s.salt...), strings.ToLower(email)...))
+	return "user-" + hex.EncodeToString(sum[:5]) + "@example.invalid"
+}
+
+// IP地址（含IPv6）替换为保留网段 198.18.0.0/15 中的IPv4地址，同一地址映射结果相同
+func (s *scrambler) ip(ip string) string {
+	sum := sha256.Sum256(append(append([]byte{}, s.salt...), ip...))
+	return fmt.Sprintf("198.%d.%d.%d", 18+sum[0]%2, sum[1], sum[2])
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	ipv4Pattern  = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\b`)
+)
+
+// 其他文本文件中的邮箱和IP地址；无法检查的非UTF-8文件（如压缩的日志）返回nil，不导出
+func (s *scrambler) personalData(data []byte) []byte {
+	if !utf8.Valid(data) {
+		return nil
+	}
+	text := emailPattern.ReplaceAllStringFunc(string(data), s.email)
+	return []byte(ipv4Pattern.ReplaceAllStringFunc(text, s.ip))
+}
+
+// dumpRule 导出时对一类文件的处理，返回nil表示不导出该文件
+type dumpRule struct {
+	pattern  string // 相对数据目录的路径（filepath.Match 模式），以 / 结尾时匹配该目录下的全部文件
+	sanitize func(d *dumper, data []byte) ([]byte, error)
+}
+
+// 不导出文件
+func dropFile(d *dumper, data []byte) ([]byte, error) { return nil, nil }
+
+// 原样导出
+func keepFile(d *dumper, data []byte) ([]byte, error) { return data, nil }
+
+// 只替换其中的邮箱和IP地址
+func scrubFile(d *dumper, data []byte) ([]byte, error) { return d.scramble.personalData(data), nil }
+
+func (r dumpRule) match(rel string) bool {
+	if strings.HasSuffix(r.pattern, "/") {
+		return strings.HasPrefix(rel, r.pattern)
+	}
+	ok, _ := filepath.Match(r.pattern, rel)
+	return ok
+}
+
+// 脱敏规则，按顺序匹配；未匹配任何规则的文件不导出
+var dumpRules = []dumpRule{
+	// 密钥、令牌和已发送的邮件（含邀请链接）
+	{"apikeys.json", dropFile},
+	{"invitations.json", dropFile},
+	{"keys/*", dropFile},
+	{"outbox/*", dropFile},
+	{"blogs/*.json", func(d *dumper, data []byte) ([]byte, error) {
+		var b Blog
+		if err := json.Unmarshal(data, &b); err != nil {
+			return nil, err
+		}
+		d.scramble.blog(&b, b.IsPublished)
+		return json.MarshalIndent(&b, "", "  ")
+	}},
+	// 历史版本：当时未发布或博客当前未发布的版本都视为草稿
+	{"revisions/*/*.json", func(d *dumper, data []byte) ([]byte, error) {
+		var b Blog
+		if err := json.Unmarshal(data, &b); err != nil {
+			return nil, err
+		}
+		d.scramble.blog(&b, b.IsPublished && d.published[b.ID])
+		return json.MarshalIndent(&b, "", "  ")
+	}},
+	// 评论：去掉邮箱摘要（未加盐，可被字典反查），打乱评论者名称和草稿下的评论
+	{"comments/*.json", func(d *dumper, data []byte) ([]byte, error) {
+		var comments []Comment
+		if err := json.Unmarshal(data, &comments); err != nil {
+			return nil, err
+		}
+		for i := range comments {
+			c := &comments[i]
+			c.AuthorName, c.EmailHash = d.scramble.text(c.AuthorName), ""
+			if !d.published[c.BlogID] {
+				c.Content = d.scramble.text(c.Content)
+			}
+		}
+		return json.MarshalIndent(comments, "", "  ")
+	}},
+	{"users.json", func(d *dumper, data []byte) ([]byte, error) {
+		var users []User
+		if err := json.Unmarshal(data, &users); err != nil {
+			return nil, err
+		}
+		for i := range users {
+			users[i].Email, users[i].DisplayName = d.scramble.email(users[i].Email), d.scramble.text(users[i].DisplayName)
+		}
+		return json.MarshalIndent(users, "", "  ")
+	}},
+	{"follows.json", func(d *dumper, data []byte) ([]byte, error) {
+		var follows []Follows
+		if err := json.Unmarshal(data, &follows); err != nil {
+			return nil, err
+		}
+		for i := range follows {
+			follows[i].FeedTokenHash, follows[i].FeedTokenAt = "", nil
+		}
+		return json.MarshalIndent(follows, "", "  ")
+	}},
+	// 配置：去掉SMTP凭据，邮件只写入本地发件箱，不向搜索引擎提交
+	{"config.json", func(d *dumper, data []byte) ([]byte, error) {
+		cfg := defaultConfig()
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, err
+		}
+		cfg.Mail.Driver, cfg.Mail.Username, cfg.Mail.Password = "log", "", ""
+		cfg.IndexNow.Key, cfg.IndexNow.Endpoints = "", nil
+		return json.MarshalIndent(&cfg, "", "  ")
+	}},
+	// 已知的其他数据：站内媒体原样导出，文本文件替换邮箱和IP地址
+	{"media/", keepFile},
+	{"authors/*.json", scrubFile},
+	{"pages/", scrubFile},
+	{"menus/*.json", scrubFile},
+	{"planet/*.json", scrubFile},
+	{"search/*.jsonl", scrubFile},
+	// 用量统计：无效密钥按客户端IP（可能是IPv6）记录
+	{"usage/*.json", func(d *dumper, data []byte) ([]byte, error) {
+		var buckets []usageBucket
+		if err := json.Unmarshal(data, &buckets); err != nil {
+			return nil, err
+		}
+		for i := range buckets {
+			if ip := strings.TrimPrefix(buckets[i].KeyID, invalidKeyIDPrefix); ip != buckets[i].KeyID {
+				buckets[i].KeyID = invalidKeyIDPrefix + d.scramble.ip(ip)
+			}
+		}
+		return json.MarshalIndent(buckets, "", "  ")
+	}},
+	{"bibliography.json", scrubFile},
+	{"synonyms.json", scrubFile},
+	{"promotions.json", scrubFile},
+	{"indexnow.jsonl", scrubFile},
+}
+
+// dumper 一次数据导出
+type dumper struct {
+	src, dst  string
+	sanitize  bool
+	scramble  *scrambler
+	published map[BlogID]bool // 当前已发布的博客
+	skip      map[string]bool // 不导出的文件（如位于数据目录中的访问日志），键为清理后的绝对路径
+	files     int
+	dropped   int
+	bytesIn   int64
+	bytesOut  int64
+}
+
+func (d *dumper) file(path string, info os.FileInfo) error {
+	rel, err := filepath.Rel(d.src, path)
+	if err != nil {
+		return err
+	}
+	rel = filepath.ToSlash(rel)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+	d.bytesIn += int64(len(data))
+
+	if d.sanitize {
+		if d.skip[absPath(path)] {
+			d.dropped++
+			return nil
+		}
+		matched := false
+		for _, rule := range dumpRules {
+			if rule.match(rel) {
+				matched = true
+				if data, err = rule.sanitize(d, data); err != nil {
+					return fmt.Errorf("failed to sanitize %s: %w", rel, err)
+				}
+				break
+			}
+		}
+		if !matched || data == nil {
+			d.dropped++
+			return nil
+		}
+	}
+
+	out := filepath.Join(d.dst, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", rel, err)
+	}
+	if err := os.WriteFile(out, data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", rel, err)
+	}
+	d.files++
+	d.bytesOut += int64(len(data))
+	return nil
+}
+
+// 清理后的绝对路径，用于比较以不同形式给出的同一文件
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// 导出数据目录到 dir/data，可直接在 dir 中启动实例；sanitize 时按 dumpRules 脱敏
+func dumpData(dir string, sanitize bool) (*dumper, error) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("%s already exists and is not empty", dir)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	d := &dumper{
+		src: dataDir, dst: filepath.Join(dir, dataDir), sanitize: sanitize,
+		scramble: &scrambler{salt: salt}, published: make(map[BlogID]bool), skip: make(map[string]bool),
+	}
+
+	blogs, err := ListBlogs()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blogs {
+		d.published[b.ID] = b.IsPublished
+	}
+	// 访问日志及其轮转文件含有IP地址和完整请求
+	if p := config.AccessLog.Path; p != "" {
+		matches, _ := filepath.Glob(p + "*")
+		for _, m := range matches {
+			d.skip[absPath(m)] = true
+		}
+	}
+
+	err = filepath.Walk(d.src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !info.Mode().IsRegular() {
+			return nil
+		}
+		return d.file(path, info)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// 导出数据集：blog dump [-sanitize] [-o dir]
+func runDump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	out := fs.String("o", "dump", "output directory; the data is written to <dir>/data")
+	sanitize := fs.Bool("sanitize", false, "scramble drafts, hash emails and IPs, and drop keys, tokens and unrecognized files")
+	fs.Parse(args)
+
+	d, err := dumpData(*out, *sanitize)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d files (%d bytes, source %d bytes) to %s", d.files, d.bytesOut, d.bytesIn, d.dst)
+	if *sanitize {
+		fmt.Printf("; %d sensitive or unrecognized files dropped", d.dropped)
+	}
+	fmt.Printf("\nStart a local instance with: cd %s && blog\n", *out)
+	return nil
+}
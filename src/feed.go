@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/xml"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 订阅中的文章数量
+const feedSize = 20
+
+// rssFeed RSS 2.0 订阅
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	SigNS     string     `xml:"xmlns:sig,attr,omitempty"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string        `xml:"title"`
+	Link          string        `xml:"link"`
+	Description   string        `xml:"description"`
+	LastBuildDate string        `xml:"lastBuildDate,omitempty"`
+	PublicKey     *rssPublicKey `xml:"sig:publicKey,omitempty"`
+	Items         []rssItem     `xml:"item"`
+}
+
+// rssPublicKey 签名扩展：站点公钥
+type rssPublicKey struct {
+	Algorithm string `xml:"algorithm,attr"`
+	KeyID     string `xml:"keyId,attr"`
+	Value     string `xml:",chardata"`
+}
+
+// rssSignature 签名扩展：文章版本签名，签名内容为该版本的规范化JSON
+type rssSignature struct {
+	Algorithm string `xml:"algorithm,attr"`
+	KeyID     string `xml:"keyId,attr"`
+	Version   int    `xml:"version,attr"`
+	Value     string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        string        `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Categories  []string      `xml:"category"`
+	Description string        `xml:"description"`
+	Content     cdata         `xml:"content:encoded"`
+	Signature   *rssSignature `xml:"sig:signature,omitempty"`
+}
+
+// cdata 以CDATA形式输出的文本
+type cdata struct {
+	Text string `xml:",cdata"`
+}
+
+// 生成站点绝对地址
+func absoluteURL(path string) string {
+	return strings.TrimRight(config.BaseURL, "/") + path
+}
+
+// 生成RSS订阅，正文为渲染后的HTML
+func renderFeed(title, link, description string, blogs []*Blog) ([]byte, error) {
+	feed := rssFeed{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:       title,
+			Link:        link,
+			Description: description,
+		},
+	}
+
+	if info, err := publicSigningKeyInfo(); err == nil {
+		feed.SigNS = signatureNS
+		feed.Channel.PublicKey = &rssPublicKey{Algorithm: info.Algorithm, KeyID: info.KeyID, Value: info.PublicKey}
+	} else {
+		log.Printf("Failed to load signing key for feed: %v", err)
+	}
+
+	for i, b := range blogs {
+		if i >= feedSize {
+			break
+		}
+		if i == 0 {
+			feed.Channel.LastBuildDate = b.UpdatedTime.Format(time.RFC1123Z)
+		}
+		url := absoluteURL("/blogs/" + string(b.ID))
+		var sig *rssSignature
+		if b.Signature != nil {
+			sig = &rssSignature{Algorithm: b.Signature.Algorithm, KeyID: b.Signature.KeyID, Version: b.Version, Value: b.Signature.Value}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       b.Title,
+			Link:        url,
+			GUID:        url,
+			PubDate:     b.CreatedTime.Format(time.RFC1123Z),
+			Categories:  b.Tags,
+			Description: excerpt(renderBlogText(b), 200),
+			Content:     cdata{renderBlogHTML(b)},
+			Signature:   sig,
+		})
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// 截取摘要
+func excerpt(text string, maxRunes int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes]) + "…"
+}
+
+// 站点订阅处理器
+func feedHandler(w http.ResponseWriter, r *http.Request) {
+	body, status, err := pageCache.Get(pageCacheKey("/feed.xml", 0, ""), func() ([]byte, []string, error) {
+		blogs, err := publishedBlogs("")
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err := renderFeed(config.SiteTitle, absoluteURL("/"), config.SiteTitle, blogs)
+		return body, []string{"index"}, err
+	})
+	if err != nil {
+		log.Printf("Failed to render feed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	sendCached(w, body, "application/rss+xml; charset=utf-8", status)
+}
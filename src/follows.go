@@ -0,0 +1,443 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Follows 用户关注的标签和作者，以及私人订阅地址的令牌
+type Follows struct {
+	UserID        int        `json:"user_id"`
+	Tags          []string   `json:"tags"`                      // 关注的标签
+	Authors       []int      `json:"authors"`                   // 关注的作者ID
+	FeedTokenHash string     `json:"feed_token_hash,omitempty"` // 私人订阅令牌的SHA-256摘要
+	FeedTokenAt   *time.Time `json:"feed_token_at,omitempty"`   // 令牌生成时间
+}
+
+// 关注存储文件
+const followFile = "data/follows.json"
+
+var followsMu sync.Mutex
+
+func loadFollows() ([]Follows, error) {
+	data, err := os.ReadFile(followFile)
+	if os.IsNotExist(err) {
+		return []Follows{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read follow file: %w", err)
+	}
+	var follows []Follows
+	if err := json.Unmarshal(data, &follows); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal follows: %w", err)
+	}
+	return follows, nil
+}
+
+func saveFollows(follows []Follows) error {
+	data, err := json.MarshalIndent(follows, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal follows: %w", err)
+	}
+	if err := os.WriteFile(followFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write follow file: %w", err)
+	}
+	return nil
+}
+
+// 用户的关注，未关注过时返回空列表
+func userFollows(userID int) (*Follows, error) {
+	follows, err := loadFollows()
+	if err != nil {
+		return nil, err
+	}
+	for i := range follows {
+		if follows[i].UserID == userID {
+			return &follows[i], nil
+		}
+	}
+	return &Follows{UserID: userID, Tags: []string{}, Authors: []int{}}, nil
+}
+
+// 修改并保存用户的关注
+func updateFollows(userID int, update func(f *Follows) error) (*Follows, error) {
+	followsMu.Lock()
+	defer followsMu.Unlock()
+
+	follows, err := loadFollows()
+	if err != nil {
+		return nil, err
+	}
+	i := 0
+	for i < len(follows) && follows[i].UserID != userID {
+		i++
+	}
+	if i == len(follows) {
+		follows = append(follows, Follows{UserID: userID, Tags: []string{}, Authors: []int{}})
+	}
+	if err := update(&follows[i]); err != nil {
+		return nil, err
+	}
+	if err := saveFollows(follows); err != nil {
+		return nil, err
+	}
+	return &follows[i], nil
+}
+
+// 校验并去重关注的标签和作者
+func normalizeFollows(tags []string, authors []int) ([]string, []int, error) {
+	cleanTags := []string{}
+	seenTags := make(map[string]bool)
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			return nil, nil, fmt.Errorf("tags must not be empty")
+		}
+		if !seenTags[strings.ToLower(t)] {
+			seenTags[strings.ToLower(t)] = true
+			cleanTags = append(cleanTags, t)
+		}
+	}
+	cleanAuthors := []int{}
+	seenAuthors := make(map[int]bool)
+	for _, id := range authors {
+		if _, err := LoadAuthor(id); err != nil {
+			return nil, nil, fmt.Errorf("author %d not found", id)
+		}
+		if !seenAuthors[id] {
+			seenAuthors[id] = true
+			cleanAuthors = append(cleanAuthors, id)
+		}
+	}
+	return cleanTags, cleanAuthors, nil
+}
+
+// 关注流：已发布且带有关注标签（不区分大小写）或出自关注作者的博客，按创建时间倒序
+func followedBlogs(f *Follows) ([]*Blog, error) {
+	blogs, err := publishedBlogs("")
+	if err != nil {
+		return nil, err
+	}
+	authors := make(map[int]bool, len(f.Authors))
+	for _, id := range f.Authors {
+		authors[id] = true
+	}
+	tags := make(map[string]bool, len(f.Tags))
+	for _, t := range f.Tags {
+		tags[strings.ToLower(t)] = true
+	}
+
+	result := []*Blog{}
+	for _, b := range blogs {
+		match := authors[b.AuthorID]
+		for _, t := range b.Tags {
+			match = match || tags[strings.ToLower(t)]
+		}
+		if match {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+// 检查请求的密钥是否属于某个用户，否则写入错误响应并返回nil
+func requireUser(w http.ResponseWriter, r *http.Request) *User {
+	key := requestAPIKey(r)
+	if key == nil {
+		sendResponse(w, false, "", nil, "API key required", http.StatusUnauthorized)
+		return nil
+	}
+	users, err := loadUsers()
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to load users", http.StatusInternalServerError)
+		return nil
+	}
+	for i := range users {
+		if key.UserID != 0 && users[i].ID == key.UserID {
+			return &users[i]
+		}
+	}
+	sendResponse(w, false, "", nil, "This API key does not belong to a user", http.StatusForbidden)
+	return nil
+}
+
+// 关注管理：GET /api/me/follows 查看，PUT 整体替换 {"tags":[...],"authors":[...]}，
+// POST {"tag":"go"} 或 {"author":2} 添加一项，DELETE ?tag=go 或 ?author=2 取消一项
+func followsHandler(w http.ResponseWriter, r *http.Request) {
+	user := requireUser(w, r)
+	if user == nil {
+		return
+	}
+
+	var (
+		follows *Follows
+		err     error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		follows, err = userFollows(user.ID)
+
+	case http.MethodPut:
+		var req struct {
+			Tags    []string `json:"tags"`
+			Authors []int    `json:"authors"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendResponse(w, false, "", nil, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		tags, authors, verr := normalizeFollows(req.Tags, req.Authors)
+		if verr != nil {
+			sendResponse(w, false, "", nil, verr.Error(), http.StatusBadRequest)
+			return
+		}
+		follows, err = updateFollows(user.ID, func(f *Follows) error {
+			f.Tags, f.Authors = tags, authors
+			return nil
+		})
+
+	case http.MethodPost:
+		var req struct {
+			Tag    string `json:"tag"`
+			Author int    `json:"author"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Tag == "" && req.Author == 0 {
+			sendResponse(w, false, "", nil, `Request must contain "tag" or "author"`, http.StatusBadRequest)
+			return
+		}
+		var verr error
+		follows, err = updateFollows(user.ID, func(f *Follows) error {
+			tags, authors := f.Tags, f.Authors
+			if req.Tag != "" {
+				tags = append(tags, req.Tag)
+			}
+			if req.Author != 0 {
+				authors = append(authors, req.Author)
+			}
+			f.Tags, f.Authors, verr = normalizeFollows(tags, authors)
+			return verr
+		})
+		if verr != nil {
+			sendResponse(w, false, "", nil, verr.Error(), http.StatusBadRequest)
+			return
+		}
+
+	case http.MethodDelete:
+		query := r.URL.Query()
+		tag := query.Get("tag")
+		author, _ := strconv.Atoi(query.Get("author"))
+		if tag == "" && author == 0 {
+			sendResponse(w, false, "", nil, "tag or author is required", http.StatusBadRequest)
+			return
+		}
+		follows, err = updateFollows(user.ID, func(f *Follows) error {
+			tags := []string{}
+			for _, t := range f.Tags {
+				if !strings.EqualFold(t, tag) {
+					tags = append(tags, t)
+				}
+			}
+			authors := []int{}
+			for _, id := range f.Authors {
+				if id != author {
+					authors = append(authors, id)
+				}
+			}
+			f.Tags, f.Authors = tags, authors
+			return nil
+		})
+
+	default:
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Failed to update follows: %v", err)
+		sendResponse(w, false, "", nil, "Failed to update follows", http.StatusInternalServerError)
+		return
+	}
+	// 令牌摘要不返回给客户端，feed_token_at 表示已生成私人订阅地址
+	view := *follows
+	view.FeedTokenHash = ""
+	message := "Follows updated successfully"
+	if r.Method == http.MethodGet {
+		message = "Follows retrieved successfully"
+	}
+	sendResponse(w, true, message, view, "", http.StatusOK)
+}
+
+// 个人关注流：GET /api/me/feed?page=&per_page=
+func myFeedHandler(w http.ResponseWriter, r *http.Request) {
+	user := requireUser(w, r)
+	if user == nil {
+		return
+	}
+	if r.Method != http.MethodGet {
+		sendBlogError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	page, perPage := 1, 20
+	if v := query.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			sendBlogError(w, r, "Invalid page", http.StatusBadRequest)
+			return
+		}
+		page = n
+	}
+	if v := query.Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			sendBlogError(w, r, "per_page must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		perPage = n
+	}
+
+	follows, err := userFollows(user.ID)
+	if err != nil {
+		sendBlogError(w, r, "Failed to load follows", http.StatusInternalServerError)
+		return
+	}
+	blogs, err := followedBlogs(follows)
+	if err != nil {
+		sendBlogError(w, r, "Failed to list blogs", http.StatusInternalServerError)
+		return
+	}
+
+	list := BlogList{Blogs: []*Blog{}, Total: len(blogs), Page: page, PerPage: perPage}
+	if start := (page - 1) * perPage; start < len(blogs) {
+		end := start + perPage
+		if end > len(blogs) {
+			end = len(blogs)
+		}
+		for _, b := range blogs[start:end] {
+			loc, err := displayLocation(r, b.AuthorID)
+			if err != nil {
+				sendBlogError(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			list.Blogs = append(list.Blogs, localizeBlog(b, loc))
+		}
+	}
+	sendBlogList(w, r, list)
+}
+
+// 生成（或更换）私人订阅令牌：POST /api/me/feed-token，旧地址随即失效；DELETE 停用私人订阅
+func feedTokenHandler(w http.ResponseWriter, r *http.Request) {
+	user := requireUser(w, r)
+	if user == nil {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		secret := make([]byte, 20)
+		if _, err := rand.Read(secret); err != nil {
+			sendResponse(w, false, "", nil, "Failed to generate token", http.StatusInternalServerError)
+			return
+		}
+		token := hex.EncodeToString(secret)
+		_, err := updateFollows(user.ID, func(f *Follows) error {
+			now := time.Now().UTC()
+			f.FeedTokenHash, f.FeedTokenAt = hashAPIKey(token), &now
+			return nil
+		})
+		if err != nil {
+			log.Printf("Failed to save feed token: %v", err)
+			sendResponse(w, false, "", nil, "Failed to save feed token", http.StatusInternalServerError)
+			return
+		}
+		// 明文令牌只在此处返回一次
+		sendResponse(w, true, "Private feed URL created; keep it secret", map[string]string{"url": absoluteURL("/feeds/" + token + ".xml")}, "", http.StatusOK)
+
+	case http.MethodDelete:
+		_, err := updateFollows(user.ID, func(f *Follows) error {
+			f.FeedTokenHash, f.FeedTokenAt = "", nil
+			return nil
+		})
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to revoke feed token", http.StatusInternalServerError)
+			return
+		}
+		sendResponse(w, true, "Private feed URL revoked", nil, "", http.StatusOK)
+
+	default:
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+var privateFeedPath = regexp.MustCompile("^/feeds/([0-9a-f]{40})\\.xml$")
+
+// 私人订阅：GET /feeds/{token}.xml，令牌即凭据，供不支持请求头的订阅阅读器使用
+func privateFeedHandler(w http.ResponseWriter, r *http.Request) {
+	matches := privateFeedPath.FindStringSubmatch(r.URL.Path)
+	if matches == nil {
+		http.NotFound(w, r)
+		return
+	}
+	follows, err := loadFollows()
+	if err != nil {
+		log.Printf("Failed to load follows: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	hash := hashAPIKey(matches[1])
+	var found *Follows
+	for i := range follows {
+		if follows[i].FeedTokenHash != "" && follows[i].FeedTokenHash == hash {
+			found = &follows[i]
+		}
+	}
+	if found == nil {
+		http.NotFound(w, r)
+		return
+	}
+	users, err := loadUsers()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	var user *User
+	for i := range users {
+		if users[i].ID == found.UserID {
+			user = &users[i]
+		}
+	}
+	if user == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	blogs, err := followedBlogs(found)
+	if err != nil {
+		log.Printf("Failed to list followed blogs: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	// 按用户生成，不经过页面缓存
+	body, err := renderFeed(config.SiteTitle+" · "+user.DisplayName, absoluteURL("/"), "Posts from the tags and authors you follow", blogs)
+	if err != nil {
+		log.Printf("Failed to render private feed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Failed to write feed: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 将博客序列化为带 front matter 的Markdown文档
+func marshalFrontMatter(b *Blog) []byte {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "id: %s\n", b.ID)
+	fmt.Fprintf(&sb, "title: %s\n", b.Title)
+	fmt.Fprintf(&sb, "author_id: %d\n", b.AuthorID)
+	fmt.Fprintf(&sb, "tags: %s\n", strings.Join(b.Tags, ", "))
+	fmt.Fprintf(&sb, "published: %t\n", b.IsPublished)
+	if !b.CreatedTime.IsZero() {
+		fmt.Fprintf(&sb, "created_at: %s\n", b.CreatedTime.Format(time.RFC3339))
+	}
+	if !b.UpdatedTime.IsZero() {
+		fmt.Fprintf(&sb, "updated_at: %s\n", b.UpdatedTime.Format(time.RFC3339))
+	}
+	if b.Signature != nil {
+		fmt.Fprintf(&sb, "version: %d\n", b.Version)
+		fmt.Fprintf(&sb, "signature: %s %s %s\n", b.Signature.Algorithm, b.Signature.KeyID, b.Signature.Value)
+	}
+	sb.WriteString("---\n\n")
+	sb.WriteString(b.Content)
+	if !strings.HasSuffix(b.Content, "\n") {
+		sb.WriteString("\n")
+	}
+	return []byte(sb.String())
+}
+
+// 解析带 front matter 的Markdown文档，更新博客的标题、标签、发布状态和内容
+func unmarshalFrontMatter(data []byte, b *Blog) error {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return fmt.Errorf("missing front matter")
+	}
+	end := strings.Index(text[4:], "\n---\n")
+	if end < 0 {
+		return fmt.Errorf("unterminated front matter")
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text[4 : 4+end]))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "title":
+			b.Title = value
+		case "tags":
+			b.Tags = nil
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					b.Tags = append(b.Tags, tag)
+				}
+			}
+		case "published":
+			published, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid published value %q", value)
+			}
+			b.IsPublished = published
+		}
+	}
+
+	b.Content = strings.TrimLeft(text[4+end+5:], "\n")
+	return nil
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 页面基础模板，各主题只替换样式
+const baseTemplate = `{{define "layout"}}<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Title}}{{.Title}} - {{end}}{{.SiteTitle}}</title>
+<link rel="alternate" type="application/rss+xml" title="{{.SiteTitle}}" href="/feed.xml">
+<style>{{template "style"}}</style>
+</head>
+<body>
+<header><a href="/">{{.SiteTitle}}</a>{{with menu "main"}}<nav>{{template "menu" .}}</nav>{{end}}</header>
+<main>{{template "content" .}}</main>
+</body>
+</html>{{end}}
+
+{{define "menu"}}<ul>{{range .}}<li><a href="{{.URL}}">{{.Label}}</a>{{if .Children}}{{template "menu" .Children}}{{end}}</li>{{end}}</ul>{{end}}
+
+{{define "list"}}<ul class="posts">
+{{range .Blogs}}<li><a href="/blogs/{{.ID}}">{{.Title}}</a> <time>{{(.CreatedTime.In $.Location).Format "2006-01-02"}}</time>{{range .Tags}} <a class="tag" href="/tags/{{pathEscape .}}">#{{.}}</a>{{end}}</li>
+{{else}}<li>暂无文章</li>
+{{end}}</ul>{{end}}
+
+{{define "index"}}{{template "layout" .}}{{end}}
+{{define "tag"}}{{template "layout" .}}{{end}}
+{{define "post"}}{{template "layout" .}}{{end}}
+{{define "archive"}}{{template "layout" .}}{{end}}
+{{define "page"}}{{template "layout" .}}{{end}}`
+
+const indexContent = `{{define "content"}}<h1>最新文章</h1>{{template "list" .}}{{end}}`
+
+const tagContent = `{{define "content"}}<h1>标签：{{.Tag}}</h1>{{template "list" .}}{{end}}`
+
+const postContent = `{{define "content"}}<article>
+<h1>{{.Blog.Title}}</h1>
+<p class="meta"><time>{{(.Blog.CreatedTime.In .Location).Format "2006-01-02 15:04 MST"}}</time>{{range .Blog.Tags}} <a class="tag" href="/tags/{{pathEscape .}}">#{{.}}</a>{{end}}</p>
+{{with .Blog.LinkPreview}}<a class="link-card" href="{{$.Blog.LinkURL}}" rel="noopener">{{if .Image}}<img src="{{.Image}}" alt="">{{end}}<strong>{{.Title}}</strong>{{if .Description}}<span>{{.Description}}</span>{{end}}<small>{{.SiteName}}</small></a>
+{{else}}{{with .Blog.LinkURL}}<p class="link"><a href="{{.}}" rel="noopener">{{.}}</a></p>
+{{end}}{{end}}{{.Content}}
+</article>{{end}}`
+
+const archiveContent = `{{define "content"}}{{if .Months}}<h1>归档</h1>
+<ul class="archive">
+{{range .Months}}<li><a href="/archive/{{.Year}}/{{printf "%02d" .Month}}">{{.Year}}年{{printf "%02d" .Month}}月</a> ({{.Count}})</li>
+{{end}}</ul>{{else}}<h1>{{.Title}}</h1>{{template "list" .}}{{end}}{{end}}`
+
+const pageContent = `{{define "content"}}<article class="page">
+{{if .Breadcrumbs}}<p class="breadcrumbs">{{range .Breadcrumbs}}<a href="{{.URL}}">{{.Label}}</a> / {{end}}</p>{{end}}
+<h1>{{.Title}}</h1>
+{{.Content}}
+{{if .Children}}<ul class="subpages">{{range .Children}}<li><a href="/{{.Path}}">{{.Title}}</a></li>{{end}}</ul>{{end}}
+</article>{{end}}`
+
+// 主题样式
+var themeStyles = map[string]string{
+	"default": `body{max-width:42rem;margin:2rem auto;padding:0 1rem;font:16px/1.7 sans-serif;color:#222}a{color:#0366d6}pre{background:#f6f8fa;padding:1rem;overflow:auto}.tag{color:#888;font-size:.9em}.callout{border-left:4px solid #0366d6;background:#f1f8ff;padding:.5rem 1rem;margin:1rem 0}.callout-warning,.callout-caution{border-color:#d73a49;background:#fff5f5}figure{margin:1rem 0}figure img{max-width:100%}.link-card{display:block;border:1px solid #ddd;padding:1rem;margin:1rem 0;color:inherit;text-decoration:none}.link-card img{max-width:100%}.link-card span,.link-card small{display:block;color:#666}`,
+	"dark":    `body{max-width:42rem;margin:2rem auto;padding:0 1rem;font:16px/1.7 sans-serif;color:#ddd;background:#1e1e1e}a{color:#58a6ff}pre{background:#2d2d2d;padding:1rem;overflow:auto}.tag{color:#999;font-size:.9em}.callout{border-left:4px solid #58a6ff;background:#262c36;padding:.5rem 1rem;margin:1rem 0}.callout-warning,.callout-caution{border-color:#f85149;background:#3a2527}figure{margin:1rem 0}figure img{max-width:100%}.link-card{display:block;border:1px solid #444;padding:1rem;margin:1rem 0;color:inherit;text-decoration:none}.link-card img{max-width:100%}.link-card span,.link-card small{display:block;color:#999}`,
+}
+
+// 主题名 -> 页面类型 -> 模板
+var themes = map[string]map[string]*template.Template{}
+
+func init() {
+	pages := map[string]string{"index": indexContent, "tag": tagContent, "post": postContent, "archive": archiveContent, "page": pageContent}
+	for name, style := range themeStyles {
+		base := template.Must(template.New("base").Funcs(templateFuncs).Parse(baseTemplate))
+		template.Must(base.New("style").Parse(style))
+		themes[name] = make(map[string]*template.Template)
+		for page, content := range pages {
+			t := template.Must(base.Clone())
+			themes[name][page] = template.Must(t.Parse(content))
+		}
+	}
+}
+
+// 模板函数：menu 获取导航菜单，page 获取静态页面，pathEscape 转义路径中的一段（如标签）
+var templateFuncs = template.FuncMap{
+	"menu":       templateMenu,
+	"pathEscape": url.PathEscape,
+	"page": func(path string) *Page {
+		page, err := LoadPage(path)
+		if err != nil {
+			return nil
+		}
+		return page
+	},
+}
+
+// 所有页面都依赖导航菜单
+func withLayoutDeps(deps ...string) []string {
+	return append(deps, "menus")
+}
+
+// pageData 模板数据
+type pageData struct {
+	SiteTitle   string
+	Title       string
+	Tag         string
+	Blog        *Blog
+	Blogs       []*Blog
+	Content     template.HTML
+	Location    *time.Location // 日期显示时区
+	Months      []archiveMonth
+	Page        *Page
+	Breadcrumbs []menuLink // 上级页面
+	Children    []*Page    // 子页面
+}
+
+// archiveMonth 归档月份
+type archiveMonth struct {
+	Year  int
+	Month int
+	Count int
+}
+
+// 选择主题：优先使用请求参数，其次使用站点配置
+func requestTheme(r *http.Request) string {
+	if name := r.URL.Query().Get("theme"); name != "" {
+		if _, ok := themes[name]; ok {
+			return name
+		}
+	}
+	return siteTheme()
+}
+
+// 站点默认主题，配置无效时使用 default
+func siteTheme() string {
+	if _, ok := themes[config.Theme]; ok {
+		return config.Theme
+	}
+	return "default"
+}
+
+// 执行模板
+func executePage(theme, page string, data pageData) ([]byte, error) {
+	data.SiteTitle = config.SiteTitle
+	var buf bytes.Buffer
+	if err := themes[theme][page].ExecuteTemplate(&buf, page, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s page: %w", page, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// 已发布的博客（按创建时间倒序），可按标签过滤
+func publishedBlogs(tag string) ([]*Blog, error) {
+	blogs, err := ListBlogs()
+	if err != nil {
+		return nil, err
+	}
+	var result []*Blog
+	for _, b := range blogs {
+		if b.IsPublished && (tag == "" || hasTag(b, tag)) {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func hasTag(b *Blog, tag string) bool {
+	for _, t := range b.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// 渲染首页
+func renderIndexPage(theme string, loc *time.Location) ([]byte, []string, error) {
+	blogs, err := publishedBlogs("")
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := executePage(theme, "index", pageData{Blogs: blogs, Location: loc})
+	return body, withLayoutDeps("index"), err
+}
+
+// 渲染标签页
+func renderTagPage(theme, tag string, loc *time.Location) ([]byte, []string, error) {
+	blogs, err := publishedBlogs(tag)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := executePage(theme, "tag", pageData{Title: tag, Tag: tag, Blogs: blogs, Location: loc})
+	return body, withLayoutDeps(tagDep(tag)), err
+}
+
+// 渲染文章页
+func renderPostPage(theme string, blog *Blog, loc *time.Location) ([]byte, []string, error) {
+	body, err := executePage(theme, "post", pageData{
+		Title:    blog.Title,
+		Blog:     blog,
+		Content:  template.HTML(renderBlogHTML(blog)),
+		Location: loc,
+	})
+	return body, withLayoutDeps(blogDep(blog.ID), "bibliography"), err
+}
+
+// 渲染归档页：month 为0时列出所有月份，否则列出该月文章（按指定时区划分日期）
+func renderArchivePage(theme string, year, month int, loc *time.Location) ([]byte, []string, error) {
+	blogs, err := publishedBlogs("")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := pageData{Title: "归档", Location: loc}
+	if month == 0 {
+		for _, b := range blogs {
+			t := b.CreatedTime.In(loc)
+			n := len(data.Months)
+			if n > 0 && data.Months[n-1].Year == t.Year() && data.Months[n-1].Month == int(t.Month()) {
+				data.Months[n-1].Count++
+				continue
+			}
+			data.Months = append(data.Months, archiveMonth{Year: t.Year(), Month: int(t.Month()), Count: 1})
+		}
+	} else {
+		data.Title = fmt.Sprintf("%d年%02d月", year, month)
+		for _, b := range blogs {
+			if t := b.CreatedTime.In(loc); t.Year() == year && int(t.Month()) == month {
+				data.Blogs = append(data.Blogs, b)
+			}
+		}
+	}
+
+	body, err := executePage(theme, "archive", data)
+	return body, withLayoutDeps("index"), err
+}
+
+// 渲染静态页面，附带上级页面导航和子页面列表
+func renderStaticPage(theme string, page *Page) ([]byte, []string, error) {
+	data := pageData{
+		Title:   page.Title,
+		Page:    page,
+		Content: template.HTML(blocksToHTML(markdownToBlocks(page.Content))),
+	}
+	deps := []string{pageDep(page.Path)}
+
+	for parent := parentPagePath(page.Path); parent != ""; parent = parentPagePath(parent) {
+		if p, err := LoadPage(parent); err == nil {
+			data.Breadcrumbs = append([]menuLink{{Label: p.Title, URL: "/" + p.Path}}, data.Breadcrumbs...)
+			deps = append(deps, pageDep(parent))
+		}
+	}
+
+	pages, err := ListPages()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range pages {
+		if parentPagePath(p.Path) == page.Path {
+			data.Children = append(data.Children, p)
+			deps = append(deps, pageDep(p.Path))
+		}
+	}
+
+	body, err := executePage(theme, "page", data)
+	return body, withLayoutDeps(deps...), err
+}
+
+// 输出HTML页面
+func sendPage(w http.ResponseWriter, body []byte, cacheStatus string) {
+	sendCached(w, body, "text/html; charset=utf-8", cacheStatus)
+}
+
+// 输出缓存的内容，并在响应头中标明缓存状态
+func sendCached(w http.ResponseWriter, body []byte, contentType, cacheStatus string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Cache", cacheStatus)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Failed to write page: %v", err)
+	}
+}
+
+// 首页处理器
+func indexPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		staticPageHandler(w, r)
+		return
+	}
+	loc, err := displayLocation(r, 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	theme := requestTheme(r)
+	body, status, err := pageCache.Get(pageCacheKey(localizedRoute("/", loc), 0, theme), func() ([]byte, []string, error) {
+		return renderIndexPage(theme, loc)
+	})
+	if err != nil {
+		log.Printf("Failed to render index page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	sendPage(w, body, status)
+}
+
+// 文章页路径
+var blogPagePath = regexp.MustCompile("^/blogs/([0-9A-Za-z_-]+)/?$")
+
+// 文章页处理器
+func blogPageHandler(w http.ResponseWriter, r *http.Request) {
+	matches := blogPagePath.FindStringSubmatch(r.URL.Path)
+	if matches == nil {
+		http.NotFound(w, r)
+		return
+	}
+	id, err := parseBlogID(matches[1])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	blog, err := LoadBlog(id)
+	if err != nil || !blog.IsPublished {
+		http.NotFound(w, r)
+		return
+	}
+
+	loc, err := displayLocation(r, blog.AuthorID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	theme := requestTheme(r)
+	body, status, err := pageCache.Get(pageCacheKey(localizedRoute("/blogs/"+string(id), loc), blog.Version, theme), func() ([]byte, []string, error) {
+		// 后台刷新时重新读取，确保使用最新内容
+		latest, err := LoadBlog(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		return renderPostPage(theme, latest, loc)
+	})
+	if err != nil {
+		log.Printf("Failed to render blog page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	sendPage(w, body, status)
+}
+
+// 标签页地址，标签作为一段路径转义（如 C# -> /tags/C%23）
+func tagURL(tag string) string {
+	return "/tags/" + url.PathEscape(tag)
+}
+
+// 标签页处理器
+func tagPageHandler(w http.ResponseWriter, r *http.Request) {
+	// 使用转义后的路径，标签中的 %2F 不会被当作路径分隔符
+	segment := strings.Trim(strings.TrimPrefix(r.URL.EscapedPath(), "/tags/"), "/")
+	if segment == "" || strings.Contains(segment, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	tag, err := url.PathUnescape(segment)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	loc, err := displayLocation(r, 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	theme := requestTheme(r)
+	body, status, err := pageCache.Get(pageCacheKey(localizedRoute(tagURL(tag), loc), 0, theme), func() ([]byte, []string, error) {
+		return renderTagPage(theme, tag, loc)
+	})
+	if err != nil {
+		log.Printf("Failed to render tag page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	sendPage(w, body, status)
+}
+
+// 归档页路径：/archive/ 或 /archive/2025/07
+var archivePagePath = regexp.MustCompile("^/archive/(?:([0-9]{4})/([0-9]{2})/?)?$")
+
+// 归档页处理器
+func archivePageHandler(w http.ResponseWriter, r *http.Request) {
+	matches := archivePagePath.FindStringSubmatch(r.URL.Path)
+	if matches == nil {
+		http.NotFound(w, r)
+		return
+	}
+	year, month := 0, 0
+	if matches[1] != "" {
+		year, _ = strconv.Atoi(matches[1])
+		month, _ = strconv.Atoi(matches[2])
+		if month < 1 || month > 12 {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	loc, err := displayLocation(r, 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	theme := requestTheme(r)
+	body, status, err := pageCache.Get(pageCacheKey(localizedRoute(r.URL.Path, loc), 0, theme), func() ([]byte, []string, error) {
+		return renderArchivePage(theme, year, month, loc)
+	})
+	if err != nil {
+		log.Printf("Failed to render archive page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	sendPage(w, body, status)
+}
+
+// 静态页面处理器：/about、/about/team 等
+func staticPageHandler(w http.ResponseWriter, r *http.Request) {
+	path, err := cleanPagePath(r.URL.Path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if _, err := LoadPage(path); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	theme := requestTheme(r)
+	body, status, err := pageCache.Get(pageCacheKey("/"+path, 0, theme), func() ([]byte, []string, error) {
+		latest, err := LoadPage(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return renderStaticPage(theme, latest)
+	})
+	if err != nil {
+		log.Printf("Failed to render page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	sendPage(w, body, status)
+}
+
+// 缓存路由附带显示时区，不同时区的页面分别缓存
+func localizedRoute(route string, loc *time.Location) string {
+	return route + "@" + loc.String()
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// halLink HAL链接
+type halLink struct {
+	Href  string `json:"href"`
+	Title string `json:"title,omitempty"`
+}
+
+// halLinks 按关系名组织的链接，值为 halLink 或 []halLink
+type halLinks map[string]interface{}
+
+// halBlog 带 _links 的博客（HAL）
+type halBlog struct {
+	*Blog
+	Links halLinks `json:"_links"`
+}
+
+// halBlogList 带 _links 的博客列表，字段与 BlogList 一致
+type halBlogList struct {
+	Blogs   []halBlog `json:"blogs"`
+	Total   int       `json:"total"`
+	Page    int       `json:"page"`
+	PerPage int       `json:"per_page"`
+	Links   halLinks  `json:"_links"`
+}
+
+// JSON:API 媒体类型
+const jsonAPIMediaType = "application/vnd.api+json"
+
+// 客户端是否通过 Accept 请求 JSON:API 格式
+func wantsJSONAPI(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), jsonAPIMediaType)
+}
+
+func blogURL(id BlogID) string { return "/api/blogs/" + string(id) }
+
+// 博客的链接：自身、作者、标签、评论和历史版本
+func blogLinks(r *http.Request, b *Blog) halLinks {
+	links := halLinks{"self": halLink{Href: blogURL(b.ID)}}
+	// 草稿的评论和历史版本只对能读取草稿的请求列出
+	if canReadBlog(r, b) {
+		links["comments"] = halLink{Href: blogURL(b.ID) + "/comments"}
+		links["revisions"] = halLink{Href: blogURL(b.ID) + "/revisions"}
+	}
+	if b.AuthorID != 0 {
+		links["author"] = halLink{Href: "/api/authors/" + strconv.Itoa(b.AuthorID)}
+	}
+	if b.IsPublished {
+		links["alternate"] = halLink{Href: "/blogs/" + string(b.ID), Title: "HTML"}
+	}
+	tags := make([]halLink, 0, len(b.Tags))
+	for _, tag := range b.Tags {
+		tags = append(tags, halLink{Href: "/api/blogs?tag=" + url.QueryEscape(tag), Title: tag})
+	}
+	links["tags"] = tags
+	return links
+}
+
+// 列表分页链接：保留原有的过滤参数，只替换页码
+func pageLinks(r *http.Request, page, perPage, total int) halLinks {
+	pageURL := func(p int) string {
+		query := r.URL.Query()
+		query.Set("page", strconv.Itoa(p))
+		query.Set("per_page", strconv.Itoa(perPage))
+		return r.URL.Path + "?" + query.Encode()
+	}
+	last := (total + perPage - 1) / perPage
+	if last < 1 {
+		last = 1
+	}
+	links := halLinks{
+		"self":  halLink{Href: pageURL(page)},
+		"first": halLink{Href: pageURL(1)},
+		"last":  halLink{Href: pageURL(last)},
+	}
+	if page > 1 {
+		links["prev"] = halLink{Href: pageURL(page - 1)}
+	}
+	if page < last {
+		links["next"] = halLink{Href: pageURL(page + 1)}
+	}
+	return links
+}
+
+// 历史版本列表的链接
+func revisionLinks(id BlogID, revisions []Revision) map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(revisions))
+	for _, rev := range revisions {
+		items = append(items, map[string]interface{}{
+			"version":      rev.Version,
+			"title":        rev.Title,
+			"updated_at":   rev.UpdatedTime,
+			"is_published": rev.IsPublished,
+			"_links":       halLinks{"self": halLink{Href: blogURL(id) + "/revisions/" + strconv.Itoa(rev.Version)}},
+		})
+	}
+	return map[string]interface{}{
+		"revisions": items,
+		"_links": halLinks{
+			"self": halLink{Href: blogURL(id) + "/revisions"},
+			"blog": halLink{Href: blogURL(id)},
+		},
+	}
+}
+
+// jsonAPIResource JSON:API 资源对象
+type jsonAPIResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id"`
+	Attributes    map[string]interface{}         `json:"attributes"`
+	Relationships map[string]jsonAPIRelationship `json:"relationships,omitempty"`
+	Links         map[string]string              `json:"links,omitempty"`
+}
+
+// jsonAPIRelationship JSON:API 关系
+type jsonAPIRelationship struct {
+	Links map[string]string `json:"links,omitempty"`
+	Data  interface{}       `json:"data,omitempty"`
+}
+
+// jsonAPIIdentifier 资源标识
+type jsonAPIIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// jsonAPIDocument JSON:API 顶层文档
+type jsonAPIDocument struct {
+	Data    interface{}            `json:"data,omitempty"`
+	Errors  []jsonAPIError         `json:"errors,omitempty"`
+	Links   map[string]string      `json:"links,omitempty"`
+	Meta    map[string]interface{} `json:"meta,omitempty"`
+	JSONAPI map[string]string      `json:"jsonapi"`
+}
+
+// jsonAPIError JSON:API 错误对象
+type jsonAPIError struct {
+	Status string `json:"status"`
+	Title  string `json:"title"`
+}
+
+// 将博客转换为 JSON:API 资源：ID 和作者移出 attributes，作者、评论和历史版本作为关系
+func blogResource(r *http.Request, b *Blog) jsonAPIResource {
+	attrs := make(map[string]interface{})
+	data, _ := json.Marshal(b)
+	json.Unmarshal(data, &attrs)
+	delete(attrs, "id")
+	delete(attrs, "author_id")
+
+	res := jsonAPIResource{
+		Type:          "blogs",
+		ID:            string(b.ID),
+		Attributes:    attrs,
+		Relationships: map[string]jsonAPIRelationship{},
+		Links:         map[string]string{"self": blogURL(b.ID)},
+	}
+	if canReadBlog(r, b) {
+		res.Relationships["comments"] = jsonAPIRelationship{Links: map[string]string{"related": blogURL(b.ID) + "/comments"}}
+		res.Relationships["revisions"] = jsonAPIRelationship{Links: map[string]string{"related": blogURL(b.ID) + "/revisions"}}
+	}
+	if b.AuthorID != 0 {
+		id := strconv.Itoa(b.AuthorID)
+		res.Relationships["author"] = jsonAPIRelationship{
+			Links: map[string]string{"related": "/api/authors/" + id},
+			Data:  jsonAPIIdentifier{Type: "authors", ID: id},
+		}
+	}
+	return res
+}
+
+func sendJSONAPI(w http.ResponseWriter, doc jsonAPIDocument, statusCode int) {
+	doc.JSONAPI = map[string]string{"version": "1.0"}
+	w.Header().Set("Content-Type", jsonAPIMediaType)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(doc); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
+
+// 按请求的格式发送错误
+func sendBlogError(w http.ResponseWriter, r *http.Request, errMsg string, statusCode int) {
+	if wantsJSONAPI(r) {
+		sendJSONAPI(w, jsonAPIDocument{Errors: []jsonAPIError{{Status: strconv.Itoa(statusCode), Title: errMsg}}}, statusCode)
+		return
+	}
+	sendResponse(w, false, "", nil, errMsg, statusCode)
+}
+
+// 发送单篇博客：默认为带 _links 的HAL格式，Accept 为 JSON:API 时返回资源文档
+func sendBlog(w http.ResponseWriter, r *http.Request, message string, b *Blog) {
+	if wantsJSONAPI(r) {
+		sendJSONAPI(w, jsonAPIDocument{Data: blogResource(r, b), Links: map[string]string{"self": blogURL(b.ID)}}, http.StatusOK)
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	sendResponse(w, true, message, halBlog{Blog: b, Links: blogLinks(r, b)}, "", http.StatusOK)
+}
+
+// 发送博客列表，附带分页链接
+func sendBlogList(w http.ResponseWriter, r *http.Request, list BlogList) {
+	links := pageLinks(r, list.Page, list.PerPage, list.Total)
+	if wantsJSONAPI(r) {
+		resources := make([]jsonAPIResource, 0, len(list.Blogs))
+		for _, b := range list.Blogs {
+			resources = append(resources, blogResource(r, b))
+		}
+		doc := jsonAPIDocument{
+			Data:  resources,
+			Links: make(map[string]string),
+			Meta:  map[string]interface{}{"total": list.Total, "page": list.Page, "per_page": list.PerPage},
+		}
+		for rel, link := range links {
+			doc.Links[rel] = link.(halLink).Href
+		}
+		sendJSONAPI(w, doc, http.StatusOK)
+		return
+	}
+
+	result := halBlogList{Blogs: make([]halBlog, 0, len(list.Blogs)), Total: list.Total, Page: list.Page, PerPage: list.PerPage, Links: links}
+	for _, b := range list.Blogs {
+		result.Blogs = append(result.Blogs, halBlog{Blog: b, Links: blogLinks(r, b)})
+	}
+	w.Header().Add("Vary", "Accept")
+	sendResponse(w, true, "Blogs retrieved successfully", result, "", http.StatusOK)
+}
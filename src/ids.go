@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// BlogID 博客ID：既可以是递增整数（旧数据），也可以是ULID等不透明字符串
+type BlogID string
+
+// 合法ID的格式，同时保证可以安全地用作文件名
+var blogIDFormat = regexp.MustCompile("^[0-9A-Za-z_-]{1,64}$")
+
+// 解析并校验博客ID
+func parseBlogID(s string) (BlogID, error) {
+	if !blogIDFormat.MatchString(s) || s == "0" {
+		return "", fmt.Errorf("invalid blog ID format")
+	}
+	return BlogID(s), nil
+}
+
+// 是否为整数ID
+func (id BlogID) isNumeric() bool {
+	n, err := strconv.Atoi(string(id))
+	return err == nil && n > 0 && strconv.Itoa(n) == string(id)
+}
+
+// MarshalJSON 整数ID仍序列化为数字，保持与旧客户端兼容
+func (id BlogID) MarshalJSON() ([]byte, error) {
+	if id.isNumeric() {
+		return []byte(id), nil
+	}
+	return json.Marshal(string(id))
+}
+
+// UnmarshalJSON 同时接受数字和字符串形式，0 和空字符串表示未指定
+func (id *BlogID) UnmarshalJSON(data []byte) error {
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid blog ID: %s", data)
+		}
+		s = n.String()
+	}
+
+	if s == "" || s == "0" {
+		*id = ""
+		return nil
+	}
+	parsed, err := parseBlogID(s)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// 为新博客分配ID，按配置使用递增整数或ULID
+func newBlogID() BlogID {
+	if config.IDScheme == "ulid" {
+		return BlogID(newULID(time.Now()))
+	}
+	return generateNewBlogID()
+}
+
+// Crockford Base32 字母表
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// 生成ULID：48位毫秒时间戳 + 80位随机数，共26个字符，按时间有序
+func newULID(t time.Time) string {
+	var b [16]byte
+	ms := uint64(t.UnixNano() / int64(time.Millisecond))
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+
+	// 128位按5位一组编码，首字符只占3位
+	var out [26]byte
+	var acc uint32
+	bits := 2
+	pos := 0
+	for _, v := range b {
+		acc = acc<<8 | uint32(v)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = crockfordAlphabet[(acc>>uint(bits))&31]
+			pos++
+		}
+	}
+	return string(out[:])
+}
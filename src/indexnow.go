@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// IndexNow 提交记录文件（每行一条JSON）
+const indexNowLogFile = "data/indexnow.jsonl"
+
+// IndexNow 单次请求最多提交的地址数
+const indexNowMaxURLs = 10000
+
+// IndexNow 密钥格式：8到128位字母、数字或连字符
+var indexNowKeyPattern = regexp.MustCompile("^[A-Za-z0-9-]{8,128}$")
+
+// indexNowRequest IndexNow 提交请求体
+type indexNowRequest struct {
+	Host        string   `json:"host"`
+	Key         string   `json:"key"`
+	KeyLocation string   `json:"keyLocation"`
+	URLList     []string `json:"urlList"`
+}
+
+// indexNowLogEntry 提交记录
+type indexNowLogEntry struct {
+	Time     time.Time `json:"time"`
+	Endpoint string    `json:"endpoint"`
+	URLs     []string  `json:"urls"`
+	Status   int       `json:"status,omitempty"` // 最后一次响应的状态码
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// indexNowNotifier 收集变更的博客地址，按批提交到配置的 IndexNow 端点
+type indexNowNotifier struct {
+	cfg        IndexNowConfig
+	client     *http.Client
+	retryDelay time.Duration // 第一次重试前的等待时长，之后每次加倍
+	mu         sync.Mutex
+	pending    map[string]bool
+	timer      *time.Timer
+	logMu      sync.Mutex
+}
+
+func newIndexNowNotifier(cfg IndexNowConfig) (*indexNowNotifier, error) {
+	if !indexNowKeyPattern.MatchString(cfg.Key) {
+		return nil, fmt.Errorf("indexnow key must be 8-128 letters, digits or dashes")
+	}
+	for _, endpoint := range cfg.Endpoints {
+		if !isWebURL(endpoint) {
+			return nil, fmt.Errorf("invalid indexnow endpoint %q", endpoint)
+		}
+	}
+	return &indexNowNotifier{
+		cfg:        cfg,
+		client:     &http.Client{Timeout: 15 * time.Second},
+		retryDelay: time.Second,
+		pending:    make(map[string]bool),
+	}, nil
+}
+
+// 博客保存监听器：发布、更新已发布博客或取消发布时加入待提交队列
+func (n *indexNowNotifier) blogSaved(old, blog *Blog) {
+	wasPublished := old != nil && old.IsPublished
+	if !blog.IsPublished && !wasPublished {
+		return
+	}
+	n.add(absoluteURL("/blogs/" + string(blog.ID)))
+}
+
+// 加入队列，批处理窗口结束后统一提交
+func (n *indexNowNotifier) add(link string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.pending[link] = true
+	if n.timer == nil {
+		n.timer = time.AfterFunc(time.Duration(n.cfg.BatchSeconds)*time.Second, n.flush)
+	}
+}
+
+// 提交当前队列中的全部地址
+func (n *indexNowNotifier) flush() {
+	n.mu.Lock()
+	urls := make([]string, 0, len(n.pending))
+	for link := range n.pending {
+		urls = append(urls, link)
+	}
+	n.pending = make(map[string]bool)
+	n.timer = nil
+	n.mu.Unlock()
+
+	if len(urls) == 0 {
+		return
+	}
+	sort.Strings(urls)
+	for start := 0; start < len(urls); start += indexNowMaxURLs {
+		end := start + indexNowMaxURLs
+		if end > len(urls) {
+			end = len(urls)
+		}
+		for _, endpoint := range n.cfg.Endpoints {
+			n.submit(endpoint, urls[start:end])
+		}
+	}
+}
+
+// 向单个端点提交，遇到网络错误、429或5xx时按指数退避重试
+func (n *indexNowNotifier) submit(endpoint string, urls []string) {
+	host := ""
+	if u, err := url.Parse(config.BaseURL); err == nil {
+		host = u.Host
+	}
+	body, err := json.Marshal(indexNowRequest{
+		Host:        host,
+		Key:         n.cfg.Key,
+		KeyLocation: absoluteURL("/" + n.cfg.Key + ".txt"),
+		URLList:     urls,
+	})
+	if err != nil {
+		log.Printf("Failed to marshal IndexNow request: %v", err)
+		return
+	}
+
+	entry := indexNowLogEntry{Endpoint: endpoint, URLs: urls}
+	backoff := n.retryDelay
+	for entry.Attempts < 1+n.cfg.MaxRetries {
+		if entry.Attempts > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		entry.Attempts++
+
+		resp, err := n.client.Post(endpoint, "application/json; charset=utf-8", bytes.NewReader(body))
+		if err != nil {
+			entry.Status, entry.Error = 0, err.Error()
+			continue
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		entry.Status, entry.Error = resp.StatusCode, ""
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusAccepted {
+			break
+		}
+		entry.Error = resp.Status
+		// 400/403/422 等表示请求或密钥有误，重试无意义
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			break
+		}
+	}
+
+	entry.Time = time.Now().UTC()
+	if entry.Error != "" {
+		log.Printf("IndexNow submission to %s failed after %d attempt(s): %s", endpoint, entry.Attempts, entry.Error)
+	}
+	n.appendLog(entry)
+}
+
+// 追加提交记录
+func (n *indexNowNotifier) appendLog(entry indexNowLogEntry) {
+	n.logMu.Lock()
+	defer n.logMu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Failed to marshal IndexNow log entry: %v", err)
+		return
+	}
+	f, err := os.OpenFile(indexNowLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Failed to open IndexNow log: %v", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		log.Printf("Failed to write IndexNow log: %v", err)
+	}
+}
+
+// 密钥文件处理器：GET /{key}.txt 返回密钥本身，供搜索引擎验证站点所有权
+func indexNowKeyHandler(key string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, key)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// indexNowStandIn 记录收到的提交，前 failures 次返回 503
+type indexNowStandIn struct {
+	mu       sync.Mutex
+	failures int
+	requests []indexNowRequest
+	received chan struct{}
+}
+
+func (s *indexNowStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req indexNowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	if s.failures > 0 {
+		s.failures--
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	if s.received != nil {
+		s.received <- struct{}{}
+	}
+}
+
+func newTestNotifier(t *testing.T, endpoint string, batchSeconds int) *indexNowNotifier {
+	t.Helper()
+	os.Remove(indexNowLogFile)
+	n, err := newIndexNowNotifier(IndexNowConfig{Key: "test-key-1234", Endpoints: []string{endpoint}, BatchSeconds: batchSeconds, MaxRetries: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.retryDelay = time.Millisecond
+	return n
+}
+
+func readIndexNowLog(t *testing.T) []indexNowLogEntry {
+	t.Helper()
+	f, err := os.Open(indexNowLogFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []indexNowLogEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e indexNowLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestIndexNowBatchesChanges(t *testing.T) {
+	standIn := &indexNowStandIn{received: make(chan struct{}, 1)}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+	n := newTestNotifier(t, srv.URL, 1)
+
+	n.blogSaved(nil, &Blog{ID: "1", IsPublished: true})
+	n.blogSaved(&Blog{ID: "2", IsPublished: true}, &Blog{ID: "2"}) // 取消发布也需要通知
+	n.blogSaved(nil, &Blog{ID: "3"})                               // 草稿不通知
+	n.blogSaved(nil, &Blog{ID: "1", IsPublished: true})
+
+	select {
+	case <-standIn.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no submission within the batch window")
+	}
+	standIn.mu.Lock()
+	defer standIn.mu.Unlock()
+	if len(standIn.requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(standIn.requests))
+	}
+	req := standIn.requests[0]
+	want := []string{absoluteURL("/blogs/1"), absoluteURL("/blogs/2")}
+	if fmt.Sprint(req.URLList) != fmt.Sprint(want) {
+		t.Errorf("urlList = %v, want %v", req.URLList, want)
+	}
+	if req.Key != "test-key-1234" || req.KeyLocation != absoluteURL("/test-key-1234.txt") {
+		t.Errorf("unexpected key fields %+v", req)
+	}
+}
+
+func TestIndexNowSplitsLargeBatches(t *testing.T) {
+	standIn := &indexNowStandIn{}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+	n := newTestNotifier(t, srv.URL, 3600)
+
+	for i := 0; i < indexNowMaxURLs+1; i++ {
+		n.add(fmt.Sprintf("https://example.com/blogs/%05d", i))
+	}
+	n.flush()
+
+	if len(standIn.requests) != 2 || len(standIn.requests[0].URLList) != indexNowMaxURLs || len(standIn.requests[1].URLList) != 1 {
+		t.Fatalf("unexpected batches: %d request(s)", len(standIn.requests))
+	}
+}
+
+func TestIndexNowRetriesServerErrors(t *testing.T) {
+	standIn := &indexNowStandIn{failures: 2}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+	n := newTestNotifier(t, srv.URL, 3600)
+
+	n.submit(srv.URL, []string{"https://example.com/blogs/1"})
+
+	if len(standIn.requests) != 3 {
+		t.Errorf("got %d attempts, want 3", len(standIn.requests))
+	}
+	entries := readIndexNowLog(t)
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1", len(entries))
+	}
+	if e := entries[0]; e.Attempts != 3 || e.Status != http.StatusAccepted || e.Error != "" || e.Endpoint != srv.URL {
+		t.Errorf("unexpected log entry %+v", e)
+	}
+}
+
+func TestIndexNowGivesUp(t *testing.T) {
+	standIn := &indexNowStandIn{failures: 100}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+	n := newTestNotifier(t, srv.URL, 3600)
+
+	n.submit(srv.URL, []string{"https://example.com/blogs/1"})
+	if len(standIn.requests) != 4 {
+		t.Errorf("got %d attempts, want 1 + 3 retries", len(standIn.requests))
+	}
+	entries := readIndexNowLog(t)
+	if len(entries) != 1 || entries[0].Status != http.StatusServiceUnavailable || entries[0].Error == "" {
+		t.Errorf("unexpected log entries %+v", entries)
+	}
+
+	// 4xx 表示请求有误，不重试
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad key", http.StatusForbidden)
+	}))
+	defer rejecting.Close()
+	n.submit(rejecting.URL, []string{"https://example.com/blogs/1"})
+	entries = readIndexNowLog(t)
+	if last := entries[len(entries)-1]; last.Attempts != 1 || last.Status != http.StatusForbidden {
+		t.Errorf("4xx was retried: %+v", last)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Mail 待发送的邮件（纯文本）
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口，按 config.Mail.Driver 选择实现
+type Mailer interface {
+	Send(m Mail) error
+}
+
+// 已注册的邮件驱动
+var mailDrivers = map[string]func(cfg MailConfig) (Mailer, error){
+	"log":  newOutboxMailer,
+	"smtp": newSMTPMailer,
+}
+
+// 按当前配置创建邮件发送器
+func newMailer() (Mailer, error) {
+	factory, ok := mailDrivers[config.Mail.Driver]
+	if !ok {
+		return nil, fmt.Errorf("unknown mail driver %q", config.Mail.Driver)
+	}
+	return factory(config.Mail)
+}
+
+// 组装邮件内容（RFC 5322）
+func formatMail(from string, m Mail) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "From: %s\r\n", from)
+	fmt.Fprintf(&sb, "To: %s\r\n", m.To)
+	fmt.Fprintf(&sb, "Subject: %s\r\n", m.Subject)
+	fmt.Fprintf(&sb, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	sb.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	sb.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+	return []byte(sb.String())
+}
+
+// outboxMailer 开发用：不实际发送，将邮件写入 data/outbox 目录
+type outboxMailer struct {
+	from string
+	dir  string
+}
+
+func newOutboxMailer(cfg MailConfig) (Mailer, error) {
+	dir := filepath.Join(dataDir, "outbox")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	return &outboxMailer{from: cfg.From, dir: dir}, nil
+}
+
+func (m *outboxMailer) Send(mail Mail) error {
+	name := newULID(time.Now()) + ".eml"
+	if err := os.WriteFile(filepath.Join(m.dir, name), formatMail(m.from, mail), 0600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	log.Printf("Mail to %s written to outbox/%s", mail.To, name)
+	return nil
+}
+
+// smtpMailer 通过SMTP服务器发送
+type smtpMailer struct {
+	cfg  MailConfig
+	auth smtp.Auth
+}
+
+func newSMTPMailer(cfg MailConfig) (Mailer, error) {
+	if cfg.SMTPAddr == "" || cfg.From == "" {
+		return nil, fmt.Errorf("smtp mail driver requires smtp_addr and from")
+	}
+	m := &smtpMailer{cfg: cfg}
+	if cfg.Username != "" {
+		host, _, err := net.SplitHostPort(cfg.SMTPAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid smtp_addr: %w", err)
+		}
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+	return m, nil
+}
+
+func (m *smtpMailer) Send(mail Mail) error {
+	if err := smtp.SendMail(m.cfg.SMTPAddr, m.auth, m.cfg.From, []string{mail.To}, formatMail(m.cfg.From, mail)); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", mail.To, err)
+	}
+	return nil
+}
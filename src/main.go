@@ -9,29 +9,42 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 )
 
 // Blog 自定义博客结构体
 type Blog struct {
-	ID          int       `json:"id"`                   // 博客ID
-	Title       string    `json:"title"`                // 标题
-	AuthorID    int       `json:"author_id"`            // 作者ID
-	Content     string    `json:"content"`              // 内容
-	Tags        []string  `json:"tags,omitempty"`       // 标签（可选）
-	CreatedTime time.Time `json:"created_at"`           // 创建时间（自动生成）
-	UpdatedTime time.Time `json:"updated_at"`           // 更新时间（自动生成）
-	IsPublished bool      `json:"is_published"`         // 是否发布（默认false）
-	ViewCount   int       `json:"view_count,omitempty"` // 浏览次数（可选）
+	ID            BlogID            `json:"id"`                       // 博客ID（整数或ULID）
+	Title         string            `json:"title"`                    // 标题
+	AuthorID      int               `json:"author_id"`                // 作者ID
+	Content       string            `json:"content"`                  // 内容
+	Blocks        []Block           `json:"blocks,omitempty"`         // 结构化内容块（可选，提供时 Content 由其生成）
+	Tags          []string          `json:"tags,omitempty"`           // 标签（可选）
+	LinkURL       string            `json:"link_url,omitempty"`       // 链接博客指向的外部地址（可选）
+	LinkPreview   *LinkPreview      `json:"link_preview,omitempty"`   // 链接预览卡片（服务端抓取生成）
+	CreatedTime   time.Time         `json:"created_at"`               // 创建时间（自动生成）
+	UpdatedTime   time.Time         `json:"updated_at"`               // 更新时间（自动生成）
+	IsPublished   bool              `json:"is_published"`             // 是否发布（默认false）
+	ViewCount     int               `json:"view_count,omitempty"`     // 浏览次数（可选）
+	Version       int               `json:"version,omitempty"`        // 内容版本号（每次编辑递增）
+	Signature     *Signature        `json:"signature,omitempty"`      // 已发布版本的ed25519签名
+	Lang          string            `json:"lang,omitempty"`           // 内容语言（BCP 47，为空时为站点语言）
+	TranslationOf BlogID            `json:"translation_of,omitempty"` // 译文对应的原文博客
+	Translation   *translationState `json:"translation,omitempty"`    // 译文的分段翻译状态
 }
 
 // ApiResponse 响应结构体
 type ApiResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success  bool        `json:"success"`
+	Message  string      `json:"message,omitempty"`
+	Data     interface{} `json:"data,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Warnings []string    `json:"warnings,omitempty"` // 不影响请求成功的问题，如未知的引用键
 }
 
 // 博客存储目录
@@ -46,14 +59,44 @@ func init() {
 
 // Save 保存博客到文件
 func (b *Blog) Save() error {
-	// 设置时间戳
+	// 设置时间戳（统一以UTC存储）
+	now := time.Now().UTC()
 	if b.CreatedTime.IsZero() {
-		b.CreatedTime = time.Now()
+		b.CreatedTime = now
 	}
-	b.UpdatedTime = time.Now()
+	b.CreatedTime = b.CreatedTime.UTC()
+	b.UpdatedTime = now
 
+	blogWriteMu.Lock()
+	defer blogWriteMu.Unlock()
+	return b.write()
+}
+
+// 博客文件的写锁：后台任务只修改个别字段时，需在锁内重新读取，避免覆盖期间的编辑
+var blogWriteMu sync.Mutex
+
+// 在锁内重新读取博客、修改并写回，不改变时间戳
+func updateBlog(id BlogID, update func(b *Blog) error) (*Blog, error) {
+	blogWriteMu.Lock()
+	defer blogWriteMu.Unlock()
+
+	blog, err := LoadBlog(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := update(blog); err != nil {
+		return nil, err
+	}
+	if err := blog.write(); err != nil {
+		return nil, err
+	}
+	return blog, nil
+}
+
+// 将博客原样写入文件，不修改时间戳
+func (b *Blog) write() error {
 	// 生成文件名
-	filename := filepath.Join(blogDir, fmt.Sprintf("%d.json", b.ID))
+	filename := filepath.Join(blogDir, string(b.ID)+".json")
 
 	// 序列化为JSON
 	data, err := json.MarshalIndent(b, "", "  ")
@@ -70,8 +113,8 @@ func (b *Blog) Save() error {
 }
 
 // 加载博客
-func LoadBlog(id int) (*Blog, error) {
-	filename := filepath.Join(blogDir, fmt.Sprintf("%d.json", id))
+func LoadBlog(id BlogID) (*Blog, error) {
+	filename := filepath.Join(blogDir, string(id)+".json")
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read blog file: %w", err)
@@ -82,20 +125,99 @@ func LoadBlog(id int) (*Blog, error) {
 		return nil, fmt.Errorf("failed to unmarshal blog: %w", err)
 	}
 
+	// 兼容旧文件中带本地时区偏移的时间
+	blog.CreatedTime = blog.CreatedTime.UTC()
+	blog.UpdatedTime = blog.UpdatedTime.UTC()
+
 	return &blog, nil
 }
 
+// ListBlogs 加载全部博客，按创建时间倒序排列
+func ListBlogs() ([]*Blog, error) {
+	files, err := os.ReadDir(blogDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blog directory: %w", err)
+	}
+
+	var blogs []*Blog
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		id, err := parseBlogID(strings.TrimSuffix(f.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		blog, err := LoadBlog(id)
+		if err != nil {
+			log.Printf("Skipping unreadable blog %s: %v", f.Name(), err)
+			continue
+		}
+		blogs = append(blogs, blog)
+	}
+
+	sort.Slice(blogs, func(i, j int) bool {
+		return blogs[i].CreatedTime.After(blogs[j].CreatedTime)
+	})
+	return blogs, nil
+}
+
+// 博客保存监听器，old 为保存前的版本（新建时为nil）
+var blogSavedHooks []func(old, blog *Blog)
+
+// 注册博客保存监听器
+func onBlogSaved(hook func(old, blog *Blog)) {
+	blogSavedHooks = append(blogSavedHooks, hook)
+}
+
+// SaveBlog 保存博客内容变更：递增版本号、写入文件并通知监听器
+func SaveBlog(blog *Blog) error {
+	if err := validateTags(blog.Tags); err != nil {
+		return err
+	}
+	old, err := LoadBlog(blog.ID)
+	if err != nil {
+		old = nil
+	}
+
+	blog.Version = 1
+	if old != nil {
+		blog.Version = old.Version + 1
+	}
+	prepareLinkPreview(old, blog)
+	keepTranslationLink(old, blog)
+	if err := signBlog(blog); err != nil {
+		return err
+	}
+
+	if err := blog.Save(); err != nil {
+		return err
+	}
+
+	for _, hook := range blogSavedHooks {
+		hook(old, blog)
+	}
+	return nil
+}
+
 // 发送JSON响应
 func sendResponse(w http.ResponseWriter, success bool, message string, data interface{}, errMsg string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	response := ApiResponse{
+	writeResponse(w, ApiResponse{
 		Success: success,
 		Message: message,
 		Data:    data,
 		Error:   errMsg,
-	}
+	}, statusCode)
+}
+
+// 发送带警告的成功响应
+func sendWarnings(w http.ResponseWriter, message string, data interface{}, warnings []string) {
+	writeResponse(w, ApiResponse{Success: true, Message: message, Data: data, Warnings: warnings}, http.StatusOK)
+}
+
+func writeResponse(w http.ResponseWriter, response ApiResponse, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("Failed to encode response: %v", err)
@@ -103,43 +225,149 @@ func sendResponse(w http.ResponseWriter, success bool, message string, data inte
 }
 
 // 获取博客ID从URL路径
-var blogIDPath = regexp.MustCompile("^/api/blogs/([0-9]+)$")
+var blogIDPath = regexp.MustCompile("^/api/blogs/([0-9A-Za-z_-]+)$")
 
-func getBlogID(r *http.Request) (int, error) {
+func getBlogID(r *http.Request) (BlogID, error) {
 	matches := blogIDPath.FindStringSubmatch(r.URL.Path)
 	if matches == nil {
-		return 0, fmt.Errorf("invalid blog ID path")
+		return "", fmt.Errorf("invalid blog ID path")
 	}
 
-	id, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return 0, fmt.Errorf("invalid blog ID format")
-	}
+	return parseBlogID(matches[1])
+}
 
-	return id, nil
+// 草稿只对带API密钥的请求可见；按ID读取单篇博客的接口都需要检查
+func canReadBlog(r *http.Request, blog *Blog) bool {
+	return blog.IsPublished || requestAPIKey(r) != nil
 }
 
 // 获取博客处理器
 func getBlogHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := getBlogID(r)
 	if err != nil {
-		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		sendBlogError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	blog, err := LoadBlog(id)
+	if err != nil || !canReadBlog(r, blog) {
+		sendBlogError(w, r, "Blog not found", http.StatusNotFound)
+		return
+	}
+
+	// Accept 为 text/markdown、text/plain 或 text/html 时返回对应的表示
+	w.Header().Set("Link", blogAlternateLinks(blog.ID))
+	if rep := negotiateBlogRepresentation(r); rep != nil {
+		w.Header().Add("Vary", "Accept")
+		sendBlogRepresentation(w, r, blog, rep)
+		return
+	}
+
+	loc, err := displayLocation(r, blog.AuthorID)
 	if err != nil {
-		sendResponse(w, false, "", nil, "Blog not found", http.StatusNotFound)
+		sendBlogError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// 增加浏览次数
-	blog.ViewCount++
-	if err := blog.Save(); err != nil {
+	// 增加浏览次数（在锁内重新读取，不覆盖并发的修改）
+	if updated, err := updateBlog(blog.ID, func(b *Blog) error {
+		b.ViewCount++
+		b.UpdatedTime = time.Now().UTC()
+		return nil
+	}); err != nil {
 		log.Printf("Failed to update view count: %v", err)
+	} else {
+		blog = updated
 	}
 
-	sendResponse(w, true, "Blog retrieved successfully", blog, "", http.StatusOK)
+	result := localizeBlog(blog, loc)
+	// ?format=blocks 时为仅有Markdown的博客生成内容块
+	if r.URL.Query().Get("format") == "blocks" && len(result.Blocks) == 0 {
+		result.Blocks = markdownToBlocks(result.Content)
+	}
+
+	sendBlog(w, r, "Blog retrieved successfully", result)
+}
+
+// BlogList 博客列表响应
+type BlogList struct {
+	Blogs   []*Blog `json:"blogs"`
+	Total   int     `json:"total"`
+	Page    int     `json:"page"`
+	PerPage int     `json:"per_page"`
+}
+
+// 博客列表处理器，支持按发布状态、标签过滤和分页；
+// 默认只列出已发布的博客，published=false（仅草稿）或 published=all 需要API密钥
+func listBlogsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	published := query.Get("published")
+	switch published {
+	case "", "true":
+		published = "true"
+	case "false", "all":
+		if requestAPIKey(r) == nil {
+			sendBlogError(w, r, "API key required to list drafts", http.StatusUnauthorized)
+			return
+		}
+	default:
+		sendBlogError(w, r, "published must be true, false or all", http.StatusBadRequest)
+		return
+	}
+
+	page, perPage := 1, 20
+	if v := query.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			sendBlogError(w, r, "Invalid page", http.StatusBadRequest)
+			return
+		}
+		page = n
+	}
+	if v := query.Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			sendBlogError(w, r, "per_page must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		perPage = n
+	}
+
+	blogs, err := ListBlogs()
+	if err != nil {
+		sendBlogError(w, r, "Failed to list blogs", http.StatusInternalServerError)
+		return
+	}
+
+	var filtered []*Blog
+	for _, b := range blogs {
+		if published != "all" && strconv.FormatBool(b.IsPublished) != published {
+			continue
+		}
+		if tag := query.Get("tag"); tag != "" && !hasTag(b, tag) {
+			continue
+		}
+		filtered = append(filtered, b)
+	}
+
+	list := BlogList{Blogs: []*Blog{}, Total: len(filtered), Page: page, PerPage: perPage}
+	if start := (page - 1) * perPage; start < len(filtered) {
+		end := start + perPage
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+		for _, b := range filtered[start:end] {
+			loc, err := displayLocation(r, b.AuthorID)
+			if err != nil {
+				sendBlogError(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			list.Blogs = append(list.Blogs, localizeBlog(b, loc))
+		}
+	}
+
+	sendBlogList(w, r, list)
 }
 
 // 创建/更新博客处理器
@@ -158,6 +386,15 @@ func saveBlogHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 提供内容块时校验结构，并由其生成Markdown内容
+	if len(blog.Blocks) > 0 {
+		if err := validateBlocks(blog.Blocks); err != nil {
+			sendResponse(w, false, "", nil, "Invalid blocks: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		blog.Content = blocksToMarkdown(blog.Blocks)
+	}
+
 	// 验证必要字段
 	if blog.Title == "" {
 		sendResponse(w, false, "", nil, "Title is required", http.StatusBadRequest)
@@ -169,6 +406,16 @@ func saveBlogHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if blog.LinkURL != "" && !isWebURL(blog.LinkURL) {
+		sendResponse(w, false, "", nil, "link_url must be an http(s) URL", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateTags(blog.Tags); err != nil {
+		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// 对于PUT请求，检查ID是否匹配URL
 	if r.Method == http.MethodPut {
 		id, err := getBlogID(r)
@@ -182,42 +429,180 @@ func saveBlogHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		// 对于POST请求，生成新ID
-		blog.ID = generateNewBlogID()
+		blog.ID = newBlogID()
 	}
 
 	// 保存博客
-	if err := blog.Save(); err != nil {
+	if err := SaveBlog(&blog); err != nil {
 		sendResponse(w, false, "", nil, "Failed to save blog", http.StatusInternalServerError)
 		return
 	}
 
-	sendResponse(w, true, "Blog saved successfully", blog, "", http.StatusOK)
+	// 引用了参考文献库中不存在的键时提示作者，但不阻止保存
+	var warnings []string
+	unknown, err := unknownCitations(&blog)
+	if err != nil {
+		log.Printf("Failed to check citations: %v", err)
+	}
+	for _, key := range unknown {
+		warnings = append(warnings, fmt.Sprintf("Unknown citation key @%s", key))
+	}
+	sendWarnings(w, "Blog saved successfully", halBlog{Blog: &blog, Links: blogLinks(r, &blog)}, warnings)
 }
 
-// 生成新博客ID（简单实现）
-func generateNewBlogID() int {
+// 校验标签：标签用作页面路径和发布目录名，不能含有路径分隔符、".."、"#"、"?" 或控制字符
+func validateTags(tags []string) error {
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("tags must not be empty")
+		}
+		if strings.ContainsAny(tag, "/\\#?") || strings.Contains(tag, "..") {
+			return fmt.Errorf("tag %q must not contain /, \\, #, ? or ..", tag)
+		}
+		for _, r := range tag {
+			if unicode.IsControl(r) {
+				return fmt.Errorf("tag %q must not contain control characters", tag)
+			}
+		}
+	}
+	return nil
+}
+
+// 生成新博客ID：现有最大整数ID加一，删除过博客或混用ULID时也不会与已有ID重复
+func generateNewBlogID() BlogID {
 	files, err := os.ReadDir(blogDir)
 	if err != nil {
 		log.Printf("Failed to read blog directory: %v", err)
-		return int(time.Now().Unix())
+		return BlogID(strconv.FormatInt(time.Now().Unix(), 10))
+	}
+	highest := 0
+	for _, f := range files {
+		if n, err := strconv.Atoi(strings.TrimSuffix(f.Name(), ".json")); err == nil && n > highest {
+			highest = n
+		}
+	}
+	next := highest + 1
+	for {
+		id := BlogID(strconv.Itoa(next))
+		if _, err := os.Stat(filepath.Join(blogDir, string(id)+".json")); os.IsNotExist(err) {
+			return id
+		}
+		next++
 	}
-	return len(files) + 1
 }
 
 func main() {
+	if err := loadConfig(configFile); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 子命令
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	// 页面渲染缓存，博客保存后使相关页面失效
+	pageCache = newRenderCache(config.RenderCache)
+	onBlogSaved(invalidateBlogPages)
+	startPreviewRefresher(time.Hour)
+
+	// 定时抓取外部订阅
+	startPlanetPoller()
+
+	// 发布或更新博客后通知搜索引擎
+	if config.IndexNow.Key != "" && len(config.IndexNow.Endpoints) > 0 {
+		notifier, err := newIndexNowNotifier(config.IndexNow)
+		if err != nil {
+			log.Fatalf("Invalid IndexNow config: %v", err)
+		}
+		onBlogSaved(notifier.blogSaved)
+		http.HandleFunc("/"+config.IndexNow.Key+".txt", indexNowKeyHandler(config.IndexNow.Key))
+	}
+
 	// 注册路由
-	http.HandleFunc("/api/blogs/", func(w http.ResponseWriter, r *http.Request) {
+	blogsHandler := func(w http.ResponseWriter, r *http.Request) {
+		if blogXLIFFPath.MatchString(r.URL.Path) {
+			xliffHandler(w, r)
+			return
+		}
 		switch r.Method {
 		case http.MethodGet:
+			if r.URL.Path == "/api/blogs" || r.URL.Path == "/api/blogs/" {
+				listBlogsHandler(w, r)
+				return
+			}
+			if blogCommentsPath.MatchString(r.URL.Path) {
+				commentsHandler(w, r)
+				return
+			}
+			if blogRevisionsPath.MatchString(r.URL.Path) {
+				revisionsHandler(w, r)
+				return
+			}
+			if blogReferencesPath.MatchString(r.URL.Path) {
+				referencesHandler(w, r)
+				return
+			}
+			if blogRepresentationPath.MatchString(r.URL.Path) {
+				blogRepresentationHandler(w, r)
+				return
+			}
 			getBlogHandler(w, r)
 		case http.MethodPost, http.MethodPut:
+			if blogUnfurlPath.MatchString(r.URL.Path) {
+				unfurlHandler(w, r)
+				return
+			}
 			saveBlogHandler(w, r)
 		default:
 			sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	})
+	}
+	http.HandleFunc("/api/blogs", blogsHandler)
+	http.HandleFunc("/api/blogs/", blogsHandler)
+	http.HandleFunc("/", indexPageHandler)
+	http.HandleFunc("/blogs/", blogPageHandler)
+	http.HandleFunc("/tags/", tagPageHandler)
+	http.HandleFunc("/archive/", archivePageHandler)
+	http.HandleFunc("/api/authors/", authorHandler)
+	http.HandleFunc("/api/blocks/", convertBlocksHandler)
+	http.HandleFunc("/feed.xml", feedHandler)
+	http.HandleFunc("/api/unfurl", linkPreviewHandler)
+	http.HandleFunc("/api/bibliography", bibliographyHandler)
+	http.HandleFunc("/api/bibliography/", bibliographyHandler)
+	http.HandleFunc("/api/pages", pagesHandler)
+	http.HandleFunc("/api/pages/", pagesHandler)
+	http.HandleFunc("/api/menus", menusHandler)
+	http.HandleFunc("/api/menus/", menusHandler)
+	http.HandleFunc("/api/admin/usage", usageHandler)
+	http.HandleFunc("/api/search", searchHandler)
+	http.HandleFunc("/api/search/click", searchClickHandler)
+	http.HandleFunc("/api/admin/search/report", searchReportHandler)
+	http.HandleFunc("/api/admin/search/synonyms", synonymsHandler)
+	http.HandleFunc("/api/admin/users", usersHandler)
+	http.HandleFunc("/api/admin/users/import", importUsersHandler)
+	http.HandleFunc("/api/invitations/accept", acceptInvitationHandler)
+	http.HandleFunc("/invite", invitePageHandler)
+	http.HandleFunc("/api/planet", planetHandler)
+	http.HandleFunc("/planet.xml", planetFeedHandler)
+	http.HandleFunc("/api/admin/planet/", planetAdminHandler)
+	http.HandleFunc("/api/admin/promote/", promoteHandler)
+	http.HandleFunc("/media/", mediaHandler)
+	http.HandleFunc("/api/admin/visibility/", visibilityHandler)
+	http.HandleFunc("/api/me/follows", followsHandler)
+	http.HandleFunc("/api/me/feed", myFeedHandler)
+	http.HandleFunc("/api/me/feed-token", feedTokenHandler)
+	http.HandleFunc("/feeds/", privateFeedHandler)
+	http.HandleFunc("/api/signing-key", signingKeyHandler)
+	http.HandleFunc("/api/verify", verifyHandler)
+
+	// API使用量统计与限流
+	startUsageTracking()
 
 	// 启动服务器
-	log.Println("Starting blog API server on :8080...")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	log.Printf("Starting blog API server on %s...", config.Addr)
+	log.Fatal(http.ListenAndServe(config.Addr, withAccessLog(usageMiddleware(http.DefaultServeMux))))
 }
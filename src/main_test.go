@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 测试在临时目录中运行，不读写仓库中的数据目录
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "blog-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.Chdir(dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := makeTestDataDirs(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// 在当前目录下创建数据目录
+func makeTestDataDirs() error {
+	for _, d := range []string{blogDir, authorDir, commentDir, menuDir, pageDir, planetDir, mediaDir, searchLogDir, usageDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestGenerateNewBlogIDAfterDeletion(t *testing.T) {
+	useTestSite(t)
+	for _, id := range []BlogID{"1", "2", "3"} {
+		saveTestBlog(t, &Blog{ID: id, Title: "Post " + string(id)})
+	}
+	if err := os.Remove(filepath.Join(blogDir, "2.json")); err != nil {
+		t.Fatal(err)
+	}
+	// 按文件数会得到已被占用的 3
+	if id := generateNewBlogID(); id != "4" {
+		t.Errorf("new ID after deleting a post = %s, want 4", id)
+	}
+	saveTestBlog(t, &Blog{ID: "01J9Z3K5X8Q2W4E6R8T0Y2V4M6", Title: "ULID post"})
+	if ids := allocateBlogIDs(2); len(ids) != 2 || ids[0] != "4" || ids[1] != "5" {
+		t.Errorf("allocated %v, want [4 5]", ids)
+	}
+}
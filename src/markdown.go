@@ -0,0 +1,361 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mdBlock Markdown块级元素
+type mdBlock struct {
+	Kind     string    // heading / paragraph / code / list / quote / hr
+	Level    int       // 标题级别
+	Ordered  bool      // 是否为有序列表
+	Lang     string    // 代码块语言
+	Text     string    // 行内文本或代码原文
+	Items    []string  // 列表项
+	Children []mdBlock // 引用块内的子元素
+}
+
+var (
+	mdHeading     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdFence       = regexp.MustCompile("^(```|~~~)\\s*([\\w+-]*)\\s*$")
+	mdRule        = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	mdBullet      = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	mdOrderedItem = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+)
+
+// 解析Markdown为块级元素列表（支持常用子集）
+func parseMarkdown(src string) []mdBlock {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var blocks []mdBlock
+	var para []string
+
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, mdBlock{Kind: "paragraph", Text: strings.Join(para, "\n")})
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case mdFence.MatchString(trimmed):
+			flush()
+			m := mdFence.FindStringSubmatch(trimmed)
+			var code []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != m[1]; i++ {
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, mdBlock{Kind: "code", Lang: m[2], Text: strings.Join(code, "\n")})
+
+		case mdHeading.MatchString(trimmed):
+			flush()
+			m := mdHeading.FindStringSubmatch(trimmed)
+			blocks = append(blocks, mdBlock{Kind: "heading", Level: len(m[1]), Text: m[2]})
+
+		case mdRule.MatchString(trimmed):
+			flush()
+			blocks = append(blocks, mdBlock{Kind: "hr"})
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(q, " "))
+			}
+			i--
+			blocks = append(blocks, mdBlock{Kind: "quote", Children: parseMarkdown(strings.Join(quoted, "\n"))})
+
+		case mdBullet.MatchString(line) || mdOrderedItem.MatchString(line):
+			flush()
+			ordered := !mdBullet.MatchString(line)
+			item := mdBullet
+			if ordered {
+				item = mdOrderedItem
+			}
+			list := mdBlock{Kind: "list", Ordered: ordered}
+			for ; i < len(lines); i++ {
+				if m := item.FindStringSubmatch(lines[i]); m != nil {
+					list.Items = append(list.Items, m[1])
+					continue
+				}
+				// 缩进的续行归入上一个列表项
+				if len(list.Items) > 0 && strings.TrimSpace(lines[i]) != "" && strings.HasPrefix(lines[i], "  ") {
+					list.Items[len(list.Items)-1] += " " + strings.TrimSpace(lines[i])
+					continue
+				}
+				break
+			}
+			i--
+			blocks = append(blocks, list)
+
+		default:
+			para = append(para, trimmed)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// 将Markdown渲染为HTML
+func renderMarkdown(src string) string {
+	var sb strings.Builder
+	renderBlocksHTML(&sb, parseMarkdown(src))
+	return sb.String()
+}
+
+func renderBlocksHTML(sb *strings.Builder, blocks []mdBlock) {
+	for _, b := range blocks {
+		switch b.Kind {
+		case "heading":
+			tag := "h" + string(rune('0'+b.Level))
+			sb.WriteString("<" + tag + ">" + renderInline(b.Text) + "</" + tag + ">\n")
+		case "paragraph":
+			sb.WriteString("<p>" + strings.ReplaceAll(renderInline(b.Text), "\n", "<br>\n") + "</p>\n")
+		case "code":
+			if b.Lang != "" {
+				sb.WriteString(`<pre><code class="language-` + html.EscapeString(b.Lang) + `">`)
+			} else {
+				sb.WriteString("<pre><code>")
+			}
+			sb.WriteString(html.EscapeString(b.Text) + "</code></pre>\n")
+		case "list":
+			tag := "ul"
+			if b.Ordered {
+				tag = "ol"
+			}
+			sb.WriteString("<" + tag + ">\n")
+			for _, item := range b.Items {
+				sb.WriteString("<li>" + renderInline(item) + "</li>\n")
+			}
+			sb.WriteString("</" + tag + ">\n")
+		case "quote":
+			sb.WriteString("<blockquote>\n")
+			renderBlocksHTML(sb, b.Children)
+			sb.WriteString("</blockquote>\n")
+		case "hr":
+			sb.WriteString("<hr>\n")
+		}
+	}
+}
+
+// 渲染行内元素：代码、图片、链接、加粗、斜体
+func renderInline(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_[]()!#", s[i+1]) >= 0:
+			sb.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				sb.WriteString("<code>" + html.EscapeString(s[i+1:i+1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if text, url, n, ok := parseInlineLink(s[i+1:]); ok {
+				sb.WriteString(`<img src="` + html.EscapeString(safeURL(url)) + `" alt="` + html.EscapeString(text) + `">`)
+				i += n + 1
+				continue
+			}
+
+		case c == '[':
+			if text, url, n, ok := parseInlineLink(s[i:]); ok {
+				sb.WriteString(`<a href="` + html.EscapeString(safeURL(url)) + `">` + renderInline(text) + `</a>`)
+				i += n
+				continue
+			}
+
+		case c == '*' || c == '_':
+			marker := s[i : i+1]
+			if strings.HasPrefix(s[i:], marker+marker) {
+				if end := strings.Index(s[i+2:], marker+marker); end > 0 {
+					sb.WriteString("<strong>" + renderInline(s[i+2:i+2+end]) + "</strong>")
+					i += end + 4
+					continue
+				}
+			} else if end := strings.Index(s[i+1:], marker); end > 0 {
+				sb.WriteString("<em>" + renderInline(s[i+1:i+1+end]) + "</em>")
+				i += end + 2
+				continue
+			}
+		}
+
+		sb.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+	return sb.String()
+}
+
+// 解析 [text](url) 形式的链接，返回文本、地址和消耗的字节数
+func parseInlineLink(s string) (text, url string, n int, ok bool) {
+	closeText := strings.Index(s, "](")
+	if !strings.HasPrefix(s, "[") || closeText < 0 {
+		return "", "", 0, false
+	}
+	closeURL := strings.IndexByte(s[closeText+2:], ')')
+	if closeURL < 0 {
+		return "", "", 0, false
+	}
+	text = s[1:closeText]
+	url = strings.TrimSpace(s[closeText+2 : closeText+2+closeURL])
+	// 去掉可选的标题部分：[text](url "title")
+	if sp := strings.IndexByte(url, ' '); sp >= 0 {
+		url = url[:sp]
+	}
+	return text, url, closeText + 3 + closeURL, true
+}
+
+// 链接地址中会截断 [text](url) 的字符按URL编码转义（地址在第一个空格或右括号处结束）
+var markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E", "\n", "%0A")
+
+func markdownLinkURL(url string) string {
+	return markdownURLEscaper.Replace(strings.TrimSpace(url))
+}
+
+// 只允许相对地址和 http、https、mailto 协议，其他协议替换为 #。
+// 浏览器会删除地址中的制表符和换行以及首尾的控制字符，判断协议前先同样处理
+func safeURL(url string) string {
+	url = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, url)
+	url = strings.TrimFunc(url, func(r rune) bool { return r <= ' ' })
+	if i := strings.IndexAny(url, ":/?#"); i > 0 && url[i] == ':' {
+		switch strings.ToLower(url[:i]) {
+		case "http", "https", "mailto":
+		default:
+			return "#"
+		}
+	}
+	return url
+}
+
+// 将Markdown渲染为纯文本（去除行内标记，保留段落结构）
+func renderMarkdownText(src string) string {
+	var sb strings.Builder
+	renderBlocksText(&sb, parseMarkdown(src), "")
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+func renderBlocksText(sb *strings.Builder, blocks []mdBlock, prefix string) {
+	for i, b := range blocks {
+		if i > 0 {
+			sb.WriteString(strings.TrimRight(prefix, " ") + "\n")
+		}
+		switch b.Kind {
+		case "heading":
+			text := plainInline(b.Text)
+			sb.WriteString(prefix + text + "\n")
+			underline := "-"
+			if b.Level == 1 {
+				underline = "="
+			}
+			sb.WriteString(prefix + strings.Repeat(underline, textWidth(text)) + "\n")
+		case "paragraph":
+			for _, line := range strings.Split(plainInline(b.Text), "\n") {
+				sb.WriteString(prefix + line + "\n")
+			}
+		case "code":
+			for _, line := range strings.Split(b.Text, "\n") {
+				sb.WriteString(prefix + "    " + line + "\n")
+			}
+		case "list":
+			for n, item := range b.Items {
+				marker := "• "
+				if b.Ordered {
+					marker = strconv.Itoa(n+1) + ". "
+				}
+				sb.WriteString(prefix + marker + plainInline(item) + "\n")
+			}
+		case "quote":
+			renderBlocksText(sb, b.Children, prefix+"> ")
+		case "hr":
+			sb.WriteString(prefix + strings.Repeat("-", 20) + "\n")
+		}
+	}
+}
+
+// 去除行内Markdown标记，链接保留为“文本 (地址)”
+func plainInline(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_[]()!#", s[i+1]) >= 0:
+			sb.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				sb.WriteString(s[i+1 : i+1+end])
+				i += end + 2
+				continue
+			}
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if text, _, n, ok := parseInlineLink(s[i+1:]); ok {
+				sb.WriteString(text)
+				i += n + 1
+				continue
+			}
+		case c == '[':
+			if text, url, n, ok := parseInlineLink(s[i:]); ok {
+				sb.WriteString(plainInline(text) + " (" + url + ")")
+				i += n
+				continue
+			}
+		case c == '*' || c == '_':
+			marker := s[i : i+1]
+			if strings.HasPrefix(s[i:], marker+marker) {
+				if end := strings.Index(s[i+2:], marker+marker); end > 0 {
+					sb.WriteString(plainInline(s[i+2 : i+2+end]))
+					i += end + 4
+					continue
+				}
+			} else if end := strings.Index(s[i+1:], marker); end > 0 {
+				sb.WriteString(plainInline(s[i+1 : i+1+end]))
+				i += end + 2
+				continue
+			}
+		}
+		sb.WriteByte(c)
+		i++
+	}
+	return sb.String()
+}
+
+// 字符显示宽度（中日韩及全角字符占两列）
+func runeWidth(r rune) int {
+	if r >= 0x1100 && (r <= 0x115F || r >= 0x2E80 && r <= 0xA4CF || r >= 0xAC00 && r <= 0xD7A3 ||
+		r >= 0xF900 && r <= 0xFAFF || r >= 0xFE30 && r <= 0xFE4F || r >= 0xFF00 && r <= 0xFF60 ||
+		r >= 0xFFE0 && r <= 0xFFE6 || r >= 0x1F300 && r <= 0x1FAFF || r >= 0x20000 && r <= 0x3FFFD) {
+		return 2
+	}
+	return 1
+}
+
+// 文本显示宽度
+func textWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestSafeURL(t *testing.T) {
+	cases := map[string]string{
+		"https://example.com/a?b=c":  "https://example.com/a?b=c",
+		"HTTP://example.com":         "HTTP://example.com",
+		"mailto:me@example.com":      "mailto:me@example.com",
+		"/blogs/1":                   "/blogs/1",
+		"../img/a.png":               "../img/a.png",
+		"#top":                       "#top",
+		"?page=2":                    "?page=2",
+		"page:1":                     "#",
+		"a/b:c":                      "a/b:c",
+		"javascript:alert(1)":        "#",
+		" JavaScript:alert(1)":       "#",
+		"java\tscript:alert(1)":      "#",
+		"java\nscript:alert(1)":      "#",
+		"\x01javascript:alert(1)":    "#",
+		"vbscript:msgbox(1)":         "#",
+		"data:text/html,<script>":    "#",
+		"data:image/png;base64,AAAA": "#",
+		"file:///etc/passwd":         "#",
+	}
+	for in, want := range cases {
+		if got := safeURL(in); got != want {
+			t.Errorf("safeURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Menu 导航菜单
+type Menu struct {
+	Name  string     `json:"name"`  // 菜单名，模板中通过 menu "main" 引用
+	Items []MenuItem `json:"items"` // 按顺序排列的菜单项
+}
+
+// MenuItem 菜单项
+type MenuItem struct {
+	Label    string     `json:"label"`              // 显示文字
+	Type     string     `json:"type"`               // 链接类型：page / post / tag / url
+	Target   string     `json:"target"`             // 页面路径、博客ID、标签名或外部地址
+	Children []MenuItem `json:"children,omitempty"` // 子菜单
+}
+
+// menuLink 解析后的菜单链接，供模板使用
+type menuLink struct {
+	Label    string
+	URL      string
+	Children []menuLink
+}
+
+// 菜单存储目录
+const menuDir = "data/menus"
+
+var menuName = regexp.MustCompile("^[a-z0-9_-]{1,32}$")
+
+func init() {
+	if err := os.MkdirAll(menuDir, 0755); err != nil {
+		log.Fatalf("Failed to create menu directory: %v", err)
+	}
+}
+
+// Save 保存菜单
+func (m *Menu) Save() error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal menu: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(menuDir, m.Name+".json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write menu file: %w", err)
+	}
+	return nil
+}
+
+// LoadMenu 加载菜单
+func LoadMenu(name string) (*Menu, error) {
+	data, err := os.ReadFile(filepath.Join(menuDir, name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read menu file: %w", err)
+	}
+	var menu Menu
+	if err := json.Unmarshal(data, &menu); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal menu: %w", err)
+	}
+	return &menu, nil
+}
+
+// ListMenus 加载全部菜单
+func ListMenus() ([]*Menu, error) {
+	files, err := os.ReadDir(menuDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read menu directory: %w", err)
+	}
+	var menus []*Menu
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		menu, err := LoadMenu(strings.TrimSuffix(f.Name(), ".json"))
+		if err != nil {
+			log.Printf("Skipping unreadable menu %s: %v", f.Name(), err)
+			continue
+		}
+		menus = append(menus, menu)
+	}
+	sort.Slice(menus, func(i, j int) bool { return menus[i].Name < menus[j].Name })
+	return menus, nil
+}
+
+// 校验菜单项及其链接目标
+func validateMenuItems(items []MenuItem, depth int) error {
+	if depth > 3 {
+		return fmt.Errorf("menus can be nested at most 3 levels deep")
+	}
+	for _, item := range items {
+		if item.Label == "" {
+			return fmt.Errorf("menu item label is required")
+		}
+		switch item.Type {
+		case "page":
+			path, err := cleanPagePath(item.Target)
+			if err != nil {
+				return fmt.Errorf("menu item %q: %w", item.Label, err)
+			}
+			if _, err := LoadPage(path); err != nil {
+				return fmt.Errorf("menu item %q: page %q does not exist", item.Label, path)
+			}
+		case "post":
+			id, err := parseBlogID(item.Target)
+			if err != nil {
+				return fmt.Errorf("menu item %q: %w", item.Label, err)
+			}
+			if _, err := LoadBlog(id); err != nil {
+				return fmt.Errorf("menu item %q: blog %q does not exist", item.Label, id)
+			}
+		case "tag":
+			if item.Target == "" || strings.Contains(item.Target, "/") {
+				return fmt.Errorf("menu item %q: invalid tag", item.Label)
+			}
+		case "url":
+			if !isWebURL(item.Target) {
+				return fmt.Errorf("menu item %q: url must be an http(s) URL", item.Label)
+			}
+		default:
+			return fmt.Errorf("menu item %q: type must be page, post, tag or url", item.Label)
+		}
+		if err := validateMenuItems(item.Children, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 菜单项对应的链接地址
+func (item MenuItem) URL() string {
+	switch item.Type {
+	case "page":
+		return "/" + strings.Trim(item.Target, "/")
+	case "post":
+		return "/blogs/" + item.Target
+	case "tag":
+		return tagURL(item.Target)
+	}
+	return item.Target
+}
+
+func resolveMenuItems(items []MenuItem) []menuLink {
+	links := make([]menuLink, 0, len(items))
+	for _, item := range items {
+		links = append(links, menuLink{Label: item.Label, URL: item.URL(), Children: resolveMenuItems(item.Children)})
+	}
+	return links
+}
+
+// 模板函数：按名称获取菜单链接，菜单不存在时返回空
+func templateMenu(name string) []menuLink {
+	menu, err := LoadMenu(name)
+	if err != nil {
+		return nil
+	}
+	return resolveMenuItems(menu.Items)
+}
+
+// 菜单处理器：GET /api/menus 列出全部菜单，/api/menus/{name} 支持 GET、PUT、DELETE（修改需要API密钥）
+func menusHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/menus"), "/")
+	if name == "" {
+		if r.Method != http.MethodGet {
+			sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		menus, err := ListMenus()
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to list menus", http.StatusInternalServerError)
+			return
+		}
+		sendResponse(w, true, "Menus retrieved successfully", menus, "", http.StatusOK)
+		return
+	}
+	if !menuName.MatchString(name) {
+		sendResponse(w, false, "", nil, "invalid menu name", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != http.MethodGet && requestAPIKey(r) == nil {
+		sendResponse(w, false, "", nil, "API key required", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		menu, err := LoadMenu(name)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Menu not found", http.StatusNotFound)
+			return
+		}
+		sendResponse(w, true, "Menu retrieved successfully", menu, "", http.StatusOK)
+
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		var menu Menu
+		if err := json.Unmarshal(body, &menu); err != nil {
+			sendResponse(w, false, "", nil, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		menu.Name = name
+		if err := validateMenuItems(menu.Items, 1); err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := menu.Save(); err != nil {
+			sendResponse(w, false, "", nil, "Failed to save menu", http.StatusInternalServerError)
+			return
+		}
+		pageCache.Invalidate("menus")
+		sendResponse(w, true, "Menu saved successfully", menu, "", http.StatusOK)
+
+	case http.MethodDelete:
+		if err := os.Remove(filepath.Join(menuDir, name+".json")); err != nil {
+			sendResponse(w, false, "", nil, "Menu not found", http.StatusNotFound)
+			return
+		}
+		pageCache.Invalidate("menus")
+		sendResponse(w, true, "Menu deleted successfully", nil, "", http.StatusOK)
+
+	default:
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
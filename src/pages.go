@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Page 静态页面（关于、联系方式等），不出现在博客列表和订阅中
+type Page struct {
+	Path        string    `json:"path"`       // 层级路径，如 about 或 about/team
+	Title       string    `json:"title"`      // 标题
+	Content     string    `json:"content"`    // Markdown内容
+	CreatedTime time.Time `json:"created_at"` // 创建时间
+	UpdatedTime time.Time `json:"updated_at"` // 更新时间
+}
+
+// 页面存储目录，子页面存放在以父页面命名的子目录中
+const pageDir = "data/pages"
+
+// 页面路径的每一段只允许小写字母、数字和连字符
+var pageSegment = regexp.MustCompile("^[a-z0-9][a-z0-9-]{0,63}$")
+
+// 与其他路由或静态发布目录冲突、不能用作页面的首段路径，新增顶级路由时需同步更新。
+// 页面路径不能含有"."，/feed.xml、/sitemap.xml 及 IndexNow 密钥文件 /{key}.txt 等带扩展名的路由不会与页面冲突
+var reservedPagePrefixes = map[string]bool{
+	"api": true, "blogs": true, "tags": true, "archive": true, "media": true, "feeds": true, "invite": true,
+}
+
+func init() {
+	if err := os.MkdirAll(pageDir, 0755); err != nil {
+		log.Fatalf("Failed to create page directory: %v", err)
+	}
+}
+
+// 校验并规范化页面路径
+func cleanPagePath(path string) (string, error) {
+	path = strings.Trim(path, "/")
+	segments := strings.Split(path, "/")
+	if path == "" || len(segments) > 5 {
+		return "", fmt.Errorf("page path must have 1 to 5 segments")
+	}
+	for _, seg := range segments {
+		if !pageSegment.MatchString(seg) {
+			return "", fmt.Errorf("invalid page path segment %q", seg)
+		}
+	}
+	if reservedPagePrefixes[segments[0]] {
+		return "", fmt.Errorf("page path %q is reserved", segments[0])
+	}
+	return path, nil
+}
+
+// 父页面路径，顶级页面返回空字符串
+func parentPagePath(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+func pageFile(path string) string {
+	return filepath.Join(pageDir, filepath.FromSlash(path)+".json")
+}
+
+// Save 保存页面
+func (p *Page) Save() error {
+	now := time.Now().UTC()
+	if p.CreatedTime.IsZero() {
+		p.CreatedTime = now
+	}
+	p.CreatedTime = p.CreatedTime.UTC()
+	p.UpdatedTime = now
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal page: %w", err)
+	}
+	filename := pageFile(p.Path)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create page directory: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write page file: %w", err)
+	}
+	return nil
+}
+
+// LoadPage 加载页面
+func LoadPage(path string) (*Page, error) {
+	data, err := os.ReadFile(pageFile(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page file: %w", err)
+	}
+	var page Page
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal page: %w", err)
+	}
+	return &page, nil
+}
+
+// ListPages 加载全部页面，按路径排序（父页面在子页面之前）
+func ListPages() ([]*Page, error) {
+	var pages []*Page
+	err := filepath.Walk(pageDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(file, ".json") {
+			return err
+		}
+		rel, err := filepath.Rel(pageDir, file)
+		if err != nil {
+			return err
+		}
+		page, err := LoadPage(strings.TrimSuffix(filepath.ToSlash(rel), ".json"))
+		if err != nil {
+			log.Printf("Skipping unreadable page %s: %v", rel, err)
+			return nil
+		}
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pages: %w", err)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Path < pages[j].Path })
+	return pages, nil
+}
+
+// 删除页面，存在子页面时拒绝删除
+func deletePage(path string) error {
+	entries, err := os.ReadDir(filepath.Join(pageDir, filepath.FromSlash(path)))
+	if err == nil && len(entries) > 0 {
+		return fmt.Errorf("page has child pages")
+	}
+	if err := os.Remove(pageFile(path)); err != nil {
+		return fmt.Errorf("failed to delete page: %w", err)
+	}
+	os.Remove(filepath.Join(pageDir, filepath.FromSlash(path)))
+	return nil
+}
+
+// 页面处理器：GET /api/pages 列出全部页面，/api/pages/{path} 支持 GET、PUT、DELETE（修改需要API密钥）
+func pagesHandler(w http.ResponseWriter, r *http.Request) {
+	rawPath := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/pages"), "/")
+	if rawPath == "" {
+		if r.Method != http.MethodGet {
+			sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		pages, err := ListPages()
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to list pages", http.StatusInternalServerError)
+			return
+		}
+		sendResponse(w, true, "Pages retrieved successfully", pages, "", http.StatusOK)
+		return
+	}
+
+	path, err := cleanPagePath(rawPath)
+	if err != nil {
+		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != http.MethodGet && requestAPIKey(r) == nil {
+		sendResponse(w, false, "", nil, "API key required", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		page, err := LoadPage(path)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Page not found", http.StatusNotFound)
+			return
+		}
+		sendResponse(w, true, "Page retrieved successfully", page, "", http.StatusOK)
+
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		var page Page
+		if err := json.Unmarshal(body, &page); err != nil {
+			sendResponse(w, false, "", nil, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		if page.Title == "" {
+			sendResponse(w, false, "", nil, "Title is required", http.StatusBadRequest)
+			return
+		}
+		if parent := parentPagePath(path); parent != "" {
+			if _, err := LoadPage(parent); err != nil {
+				sendResponse(w, false, "", nil, "Parent page does not exist: "+parent, http.StatusBadRequest)
+				return
+			}
+		}
+		if existing, err := LoadPage(path); err == nil {
+			page.CreatedTime = existing.CreatedTime
+		}
+		page.Path = path
+		if err := page.Save(); err != nil {
+			sendResponse(w, false, "", nil, "Failed to save page", http.StatusInternalServerError)
+			return
+		}
+		pageCache.Invalidate(pageDep(path), "menus")
+		sendResponse(w, true, "Page saved successfully", page, "", http.StatusOK)
+
+	case http.MethodDelete:
+		if _, err := LoadPage(path); err != nil {
+			sendResponse(w, false, "", nil, "Page not found", http.StatusNotFound)
+			return
+		}
+		if err := deletePage(path); err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusConflict)
+			return
+		}
+		pageCache.Invalidate(pageDep(path), "menus")
+		sendResponse(w, true, "Page deleted successfully", nil, "", http.StatusOK)
+
+	default:
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func pageDep(path string) string { return "page:" + path }
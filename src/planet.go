@@ -0,0 +1,754 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PlanetSource 订阅的外部博客
+type PlanetSource struct {
+	ID           string    `json:"id"`                      // 来源标识（小写字母、数字和连字符）
+	URL          string    `json:"url"`                     // RSS或Atom订阅地址
+	Title        string    `json:"title,omitempty"`         // 来源名称，为空时使用订阅自身的标题
+	AuthorID     int       `json:"author_id,omitempty"`     // 归属的站内作者
+	Mode         string    `json:"mode"`                    // stream 进入聚合流 / link_posts 导入为链接博客
+	Publish      bool      `json:"publish,omitempty"`       // link_posts 模式下导入后直接发布
+	Tags         []string  `json:"tags,omitempty"`          // link_posts 模式下附加的标签
+	ETag         string    `json:"etag,omitempty"`          // 上次响应的 ETag，用于条件请求
+	LastModified string    `json:"last_modified,omitempty"` // 上次响应的 Last-Modified
+	CheckedAt    time.Time `json:"checked_at,omitempty"`    // 上次抓取时间
+	LastStatus   int       `json:"last_status,omitempty"`   // 上次响应状态码
+	LastError    string    `json:"last_error,omitempty"`    // 上次抓取错误
+}
+
+// PlanetEntry 从外部订阅导入的条目
+type PlanetEntry struct {
+	Key         string    `json:"key"`                 // 去重键：来源ID + GUID摘要
+	SourceID    string    `json:"source_id"`           // 来源
+	SourceTitle string    `json:"source_title"`        // 来源名称（署名用）
+	AuthorID    int       `json:"author_id,omitempty"` // 归属的站内作者
+	GUID        string    `json:"guid"`                // 条目在原订阅中的ID
+	Title       string    `json:"title"`
+	Link        string    `json:"link"`
+	Author      string    `json:"author,omitempty"`  // 订阅中的作者名
+	Summary     string    `json:"summary,omitempty"` // 纯文本摘要
+	Published   time.Time `json:"published"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	BlogID      BlogID    `json:"blog_id,omitempty"` // link_posts 模式下导入的博客
+}
+
+// 聚合数据目录
+const planetDir = "data/planet"
+
+var (
+	planetSourcesFile = filepath.Join(planetDir, "sources.json")
+	planetEntriesFile = filepath.Join(planetDir, "entries.json")
+	planetMu          sync.Mutex
+	planetSourceID    = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+)
+
+func init() {
+	if err := os.MkdirAll(planetDir, 0755); err != nil {
+		log.Fatalf("Failed to create planet directory: %v", err)
+	}
+}
+
+func loadPlanetSources() ([]PlanetSource, error) {
+	data, err := os.ReadFile(planetSourcesFile)
+	if os.IsNotExist(err) {
+		return []PlanetSource{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read planet sources: %w", err)
+	}
+	var sources []PlanetSource
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal planet sources: %w", err)
+	}
+	return sources, nil
+}
+
+func savePlanetSources(sources []PlanetSource) error {
+	data, err := json.MarshalIndent(sources, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal planet sources: %w", err)
+	}
+	if err := os.WriteFile(planetSourcesFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write planet sources: %w", err)
+	}
+	return nil
+}
+
+func loadPlanetEntries() ([]PlanetEntry, error) {
+	data, err := os.ReadFile(planetEntriesFile)
+	if os.IsNotExist(err) {
+		return []PlanetEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read planet entries: %w", err)
+	}
+	var entries []PlanetEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal planet entries: %w", err)
+	}
+	return entries, nil
+}
+
+func savePlanetEntries(entries []PlanetEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal planet entries: %w", err)
+	}
+	if err := os.WriteFile(planetEntriesFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write planet entries: %w", err)
+	}
+	return nil
+}
+
+// 校验来源配置
+func validatePlanetSource(s *PlanetSource) error {
+	if !planetSourceID.MatchString(s.ID) {
+		return fmt.Errorf("id must be lowercase letters, digits and hyphens")
+	}
+	if !isWebURL(s.URL) {
+		return fmt.Errorf("url must be an http(s) URL")
+	}
+	if s.Mode == "" {
+		s.Mode = "stream"
+	}
+	if s.Mode != "stream" && s.Mode != "link_posts" {
+		return fmt.Errorf("mode must be stream or link_posts")
+	}
+	return nil
+}
+
+// 新增或更新来源（按ID），保留已有的抓取状态
+func savePlanetSource(src PlanetSource) (*PlanetSource, error) {
+	if err := validatePlanetSource(&src); err != nil {
+		return nil, err
+	}
+	planetMu.Lock()
+	defer planetMu.Unlock()
+	sources, err := loadPlanetSources()
+	if err != nil {
+		return nil, err
+	}
+	for i := range sources {
+		if sources[i].ID == src.ID {
+			if sources[i].URL == src.URL {
+				src.ETag, src.LastModified = sources[i].ETag, sources[i].LastModified
+				src.CheckedAt, src.LastStatus, src.LastError = sources[i].CheckedAt, sources[i].LastStatus, sources[i].LastError
+			}
+			sources[i] = src
+			return &src, savePlanetSources(sources)
+		}
+	}
+	sources = append(sources, src)
+	return &src, savePlanetSources(sources)
+}
+
+// 删除来源，已导入的条目保留
+func removePlanetSource(id string) error {
+	planetMu.Lock()
+	defer planetMu.Unlock()
+	sources, err := loadPlanetSources()
+	if err != nil {
+		return err
+	}
+	for i := range sources {
+		if sources[i].ID == id {
+			return savePlanetSources(append(sources[:i], sources[i+1:]...))
+		}
+	}
+	return fmt.Errorf("planet source %q not found", id)
+}
+
+// 订阅文档：兼容 RSS 2.0、RSS 1.0（RDF）和 Atom 1.0
+type feedDocument struct {
+	XMLName xml.Name
+	Title   string          `xml:"title"`
+	Channel *feedChannel    `xml:"channel"`
+	Items   []feedRSSItem   `xml:"item"` // RSS 1.0 的条目位于根元素下
+	Entries []feedAtomEntry `xml:"entry"`
+}
+
+type feedChannel struct {
+	Title string        `xml:"title"`
+	Items []feedRSSItem `xml:"item"`
+}
+
+type feedRSSItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Author      string `xml:"author"`
+	Description string `xml:"description"`
+}
+
+type feedAtomEntry struct {
+	Title     string `xml:"title"`
+	ID        string `xml:"id"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Links     []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Author struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+// feedItem 统一后的订阅条目
+type feedItem struct {
+	GUID, Title, Link, Author, Summary string
+	Published                          time.Time
+}
+
+var feedDateLayouts = []string{
+	time.RFC1123Z, time.RFC1123, time.RFC3339Nano, time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05", "2006-01-02",
+}
+
+func parseFeedDate(values ...string) time.Time {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		for _, layout := range feedDateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC()
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// 将订阅中的HTML摘要转换为纯文本
+func feedSummary(s string) string {
+	return excerpt(html.UnescapeString(htmlTag.ReplaceAllString(s, " ")), 300)
+}
+
+// 解析订阅，返回订阅标题和条目
+func parseFeed(data []byte) (string, []feedItem, error) {
+	var doc feedDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return "", nil, fmt.Errorf("invalid feed: %w", err)
+	}
+
+	var items []feedItem
+	title := strings.TrimSpace(doc.Title)
+	rssItems := doc.Items
+	if doc.Channel != nil {
+		title = strings.TrimSpace(doc.Channel.Title)
+		rssItems = append(rssItems, doc.Channel.Items...)
+	}
+	for _, it := range rssItems {
+		items = append(items, feedItem{
+			GUID:      firstNonEmpty(strings.TrimSpace(it.GUID), strings.TrimSpace(it.Link)),
+			Title:     strings.TrimSpace(it.Title),
+			Link:      strings.TrimSpace(it.Link),
+			Author:    strings.TrimSpace(firstNonEmpty(it.Creator, it.Author)),
+			Summary:   feedSummary(it.Description),
+			Published: parseFeedDate(it.PubDate, it.Date),
+		})
+	}
+	for _, e := range doc.Entries {
+		link := ""
+		for _, l := range e.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = strings.TrimSpace(l.Href)
+				break
+			}
+		}
+		items = append(items, feedItem{
+			GUID:      firstNonEmpty(strings.TrimSpace(e.ID), link),
+			Title:     strings.TrimSpace(e.Title),
+			Link:      link,
+			Author:    strings.TrimSpace(e.Author.Name),
+			Summary:   feedSummary(firstNonEmpty(e.Summary, e.Content)),
+			Published: parseFeedDate(e.Published, e.Updated),
+		})
+	}
+	if doc.Channel == nil && len(doc.Entries) == 0 && len(doc.Items) == 0 && doc.XMLName.Local != "feed" {
+		return "", nil, fmt.Errorf("not an RSS or Atom feed")
+	}
+	return title, items, nil
+}
+
+// 规范化链接用于跨来源去重：去掉片段、utm_ 参数和末尾斜杠
+func normalizeEntryLink(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme, u.Host, u.Fragment = strings.ToLower(u.Scheme), strings.ToLower(u.Host), ""
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(key, "utm_") {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return strings.TrimSuffix(u.String(), "/")
+}
+
+// planetFetchResult 一次抓取的结果
+type planetFetchResult struct {
+	SourceID   string `json:"source_id"`
+	Status     int    `json:"status,omitempty"`
+	NotChanged bool   `json:"not_modified,omitempty"`
+	New        int    `json:"new"`
+	Duplicates int    `json:"duplicates"`
+	Error      string `json:"error,omitempty"`
+}
+
+var (
+	planetClient     *http.Client
+	planetClientOnce sync.Once
+)
+
+// 抓取订阅使用的客户端，与链接预览相同的地址限制和重定向策略
+func planetHTTPClient() *http.Client {
+	planetClientOnce.Do(func() {
+		planetClient = newUnfurler(UnfurlConfig{
+			TimeoutSeconds: config.Planet.TimeoutSeconds,
+			AllowPrivate:   config.Planet.AllowPrivate,
+		}).client
+	})
+	return planetClient
+}
+
+// 条件请求抓取一个来源：304 时不做处理，新条目按来源模式进入聚合流或导入为链接博客
+func fetchPlanetSource(ctx context.Context, src *PlanetSource, entries []PlanetEntry) ([]PlanetEntry, planetFetchResult) {
+	result := planetFetchResult{SourceID: src.ID}
+	src.CheckedAt = time.Now().UTC()
+	fail := func(err error) ([]PlanetEntry, planetFetchResult) {
+		src.LastError, result.Error = err.Error(), err.Error()
+		return entries, result
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.URL, nil)
+	if err != nil {
+		return fail(err)
+	}
+	req.Header.Set("User-Agent", "blog-planet/1.0 (+"+config.BaseURL+")")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.5")
+	if src.ETag != "" {
+		req.Header.Set("If-None-Match", src.ETag)
+	}
+	if src.LastModified != "" {
+		req.Header.Set("If-Modified-Since", src.LastModified)
+	}
+	resp, err := planetHTTPClient().Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer resp.Body.Close()
+	src.LastStatus, result.Status = resp.StatusCode, resp.StatusCode
+
+	if resp.StatusCode == http.StatusNotModified {
+		src.LastError, result.NotChanged = "", true
+		return entries, result
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fail(fmt.Errorf("unexpected status %s", resp.Status))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, config.Planet.MaxBytes))
+	if err != nil {
+		return fail(err)
+	}
+	feedTitle, items, err := parseFeed(body)
+	if err != nil {
+		return fail(err)
+	}
+	src.ETag, src.LastModified, src.LastError = resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), ""
+
+	seenKeys := make(map[string]bool, len(entries))
+	seenLinks := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		seenKeys[e.Key] = true
+		if e.Link != "" {
+			seenLinks[normalizeEntryLink(e.Link)] = true
+		}
+	}
+	sourceTitle := firstNonEmpty(src.Title, feedTitle, src.ID)
+	now := time.Now().UTC()
+	for _, it := range items {
+		if it.GUID == "" || it.Title == "" && it.Link == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(it.GUID))
+		key := src.ID + ":" + hex.EncodeToString(sum[:8])
+		// 同一来源按GUID去重，不同来源（如转载或多个聚合）按链接去重
+		if seenKeys[key] || it.Link != "" && seenLinks[normalizeEntryLink(it.Link)] {
+			result.Duplicates++
+			continue
+		}
+		seenKeys[key] = true
+		seenLinks[normalizeEntryLink(it.Link)] = true
+
+		entry := PlanetEntry{
+			Key: key, SourceID: src.ID, SourceTitle: sourceTitle, AuthorID: src.AuthorID,
+			GUID: it.GUID, Title: firstNonEmpty(it.Title, it.Link), Link: it.Link, Author: it.Author,
+			Summary: it.Summary, Published: it.Published, FetchedAt: now,
+		}
+		if entry.Published.IsZero() {
+			entry.Published = now
+		}
+		if src.Mode == "link_posts" && isWebURL(entry.Link) {
+			id, err := importPlanetLinkPost(src, &entry)
+			if err != nil {
+				log.Printf("Failed to import planet entry %s: %v", entry.Link, err)
+				continue
+			}
+			entry.BlogID = id
+		}
+		entries = append(entries, entry)
+		result.New++
+	}
+	return entries, result
+}
+
+// 将条目导入为链接博客，正文为摘要和原文署名
+func importPlanetLinkPost(src *PlanetSource, e *PlanetEntry) (BlogID, error) {
+	content := e.Summary
+	if content != "" {
+		content += "\n\n"
+	}
+	by := ""
+	if e.Author != "" {
+		by = " · " + escapeMarkdown(e.Author)
+	}
+	content += fmt.Sprintf("*原文发表于 [%s](%s)%s*", escapeMarkdown(e.SourceTitle), markdownLinkURL(e.Link), by)
+
+	blog := &Blog{
+		ID:          newBlogID(),
+		Title:       e.Title,
+		AuthorID:    src.AuthorID,
+		Content:     content,
+		Tags:        append([]string{"planet"}, src.Tags...),
+		LinkURL:     e.Link,
+		CreatedTime: e.Published,
+		IsPublished: src.Publish,
+	}
+	if err := SaveBlog(blog); err != nil {
+		return "", err
+	}
+	return blog.ID, nil
+}
+
+// 抓取全部（或指定的）来源并保存状态和新条目
+func fetchPlanet(ctx context.Context, only string) ([]planetFetchResult, error) {
+	planetMu.Lock()
+	defer planetMu.Unlock()
+
+	sources, err := loadPlanetSources()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := loadPlanetEntries()
+	if err != nil {
+		return nil, err
+	}
+	results := []planetFetchResult{}
+	for i := range sources {
+		if only != "" && sources[i].ID != only {
+			continue
+		}
+		var result planetFetchResult
+		entries, result = fetchPlanetSource(ctx, &sources[i], entries)
+		if result.Error != "" {
+			log.Printf("Planet source %s: %s", sources[i].ID, result.Error)
+		}
+		results = append(results, result)
+	}
+
+	// 按发布时间倒序，超出上限时丢弃最旧的聚合流条目（导入为博客的条目保留去重记录）
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Published.After(entries[j].Published) })
+	if max := config.Planet.MaxEntries; max > 0 && len(entries) > max {
+		kept := entries[:0]
+		for i, e := range entries {
+			if i < max || e.BlogID != "" {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+	}
+	if err := savePlanetEntries(entries); err != nil {
+		return nil, err
+	}
+	if err := savePlanetSources(sources); err != nil {
+		return nil, err
+	}
+	pageCache.Invalidate("planet")
+	return results, nil
+}
+
+// 定时抓取全部来源
+func startPlanetPoller() {
+	interval := time.Duration(config.Planet.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		tick := time.Tick(interval)
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if _, err := fetchPlanet(ctx, ""); err != nil {
+				log.Printf("Failed to fetch planet sources: %v", err)
+			}
+			cancel()
+			<-tick
+		}
+	}()
+}
+
+// 聚合流中的条目（不含已导入为博客的条目）
+func planetStream() ([]PlanetEntry, error) {
+	entries, err := loadPlanetEntries()
+	if err != nil {
+		return nil, err
+	}
+	stream := []PlanetEntry{}
+	for _, e := range entries {
+		if e.BlogID == "" {
+			stream = append(stream, e)
+		}
+	}
+	return stream, nil
+}
+
+// 聚合流：GET /api/planet?page=&per_page=&source=
+func planetHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	perPage, _ := strconv.Atoi(query.Get("per_page"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+	stream, err := planetStream()
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to load planet", http.StatusInternalServerError)
+		return
+	}
+	if source := query.Get("source"); source != "" {
+		filtered := []PlanetEntry{}
+		for _, e := range stream {
+			if e.SourceID == source {
+				filtered = append(filtered, e)
+			}
+		}
+		stream = filtered
+	}
+
+	result := struct {
+		Entries []PlanetEntry `json:"entries"`
+		Total   int           `json:"total"`
+		Page    int           `json:"page"`
+		PerPage int           `json:"per_page"`
+		Links   halLinks      `json:"_links"`
+	}{Entries: []PlanetEntry{}, Total: len(stream), Page: page, PerPage: perPage, Links: pageLinks(r, page, perPage, len(stream))}
+	if start := (page - 1) * perPage; start < len(stream) {
+		end := start + perPage
+		if end > len(stream) {
+			end = len(stream)
+		}
+		result.Entries = stream[start:end]
+	}
+	sendResponse(w, true, "Planet retrieved successfully", result, "", http.StatusOK)
+}
+
+// 聚合流订阅：GET /planet.xml
+func planetFeedHandler(w http.ResponseWriter, r *http.Request) {
+	body, status, err := pageCache.Get(pageCacheKey("/planet.xml", 0, ""), func() ([]byte, []string, error) {
+		stream, err := planetStream()
+		if err != nil {
+			return nil, nil, err
+		}
+		feed := rssFeed{
+			Version:   "2.0",
+			ContentNS: "http://purl.org/rss/1.0/modules/content/",
+			Channel: rssChannel{
+				Title:       config.SiteTitle + " Planet",
+				Link:        absoluteURL("/planet.xml"),
+				Description: "Posts from around the team",
+			},
+		}
+		for i, e := range stream {
+			if i >= feedSize {
+				break
+			}
+			feed.Channel.Items = append(feed.Channel.Items, rssItem{
+				Title:       e.SourceTitle + ": " + e.Title,
+				Link:        e.Link,
+				GUID:        firstNonEmpty(e.Link, e.GUID),
+				PubDate:     e.Published.Format(time.RFC1123Z),
+				Categories:  []string{e.SourceTitle},
+				Description: e.Summary,
+			})
+		}
+		data, err := xml.MarshalIndent(feed, "", "  ")
+		if err != nil {
+			return nil, nil, err
+		}
+		return append([]byte(xml.Header), data...), []string{"planet"}, nil
+	})
+	if err != nil {
+		log.Printf("Failed to render planet feed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	sendCached(w, body, "application/rss+xml; charset=utf-8", status)
+}
+
+// 来源管理：GET/POST /api/admin/planet/sources，DELETE /api/admin/planet/sources/{id}，
+// POST /api/admin/planet/fetch[?source=id] 立即抓取（均需要管理员密钥）
+func planetAdminHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/api/admin/planet/")
+	switch {
+	case rest == "fetch" && r.Method == http.MethodPost:
+		results, err := fetchPlanet(r.Context(), r.URL.Query().Get("source"))
+		if err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendResponse(w, true, "Planet fetched", results, "", http.StatusOK)
+
+	case rest == "sources" && r.Method == http.MethodGet:
+		sources, err := loadPlanetSources()
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to load planet sources", http.StatusInternalServerError)
+			return
+		}
+		sendResponse(w, true, "Planet sources retrieved successfully", sources, "", http.StatusOK)
+
+	case rest == "sources" && r.Method == http.MethodPost:
+		var src PlanetSource
+		if err := json.NewDecoder(r.Body).Decode(&src); err != nil {
+			sendResponse(w, false, "", nil, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		saved, err := savePlanetSource(src)
+		if err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendResponse(w, true, "Planet source saved successfully", saved, "", http.StatusOK)
+
+	case strings.HasPrefix(rest, "sources/") && r.Method == http.MethodDelete:
+		if err := removePlanetSource(strings.TrimPrefix(rest, "sources/")); err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusNotFound)
+			return
+		}
+		sendResponse(w, true, "Planet source removed", nil, "", http.StatusOK)
+
+	default:
+		sendResponse(w, false, "", nil, "Not found", http.StatusNotFound)
+	}
+}
+
+// 管理聚合来源：blog planet add|list|remove|fetch
+func runPlanet(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: blog planet add|list|remove|fetch")
+	}
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("planet add", flag.ExitOnError)
+		id := fs.String("id", "", "source ID, e.g. alice")
+		feedURL := fs.String("url", "", "RSS or Atom feed URL")
+		title := fs.String("title", "", "display name (defaults to the feed title)")
+		author := fs.Int("author", 0, "attribute entries to this author ID")
+		mode := fs.String("mode", "stream", "stream or link_posts")
+		publish := fs.Bool("publish", false, "publish imported link posts immediately")
+		tags := fs.String("tags", "", "comma-separated tags for imported link posts")
+		fs.Parse(args[1:])
+		src := PlanetSource{ID: *id, URL: *feedURL, Title: *title, AuthorID: *author, Mode: *mode, Publish: *publish}
+		for _, t := range strings.Split(*tags, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				src.Tags = append(src.Tags, t)
+			}
+		}
+		if _, err := savePlanetSource(src); err != nil {
+			return err
+		}
+		fmt.Printf("Saved planet source %s (%s)\n", src.ID, src.URL)
+		return nil
+
+	case "list":
+		sources, err := loadPlanetSources()
+		if err != nil {
+			return err
+		}
+		for _, s := range sources {
+			state := "never fetched"
+			if !s.CheckedAt.IsZero() {
+				state = fmt.Sprintf("checked %s, status %d", s.CheckedAt.Format(time.RFC3339), s.LastStatus)
+			}
+			if s.LastError != "" {
+				state += ", error: " + s.LastError
+			}
+			fmt.Printf("%-16s %-10s %s (%s)\n", s.ID, s.Mode, s.URL, state)
+		}
+		return nil
+
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: blog planet remove <id>")
+		}
+		return removePlanetSource(args[1])
+
+	case "fetch":
+		only := ""
+		if len(args) > 1 {
+			only = args[1]
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		results, err := fetchPlanet(ctx, only)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			switch {
+			case r.Error != "":
+				fmt.Printf("%-16s error: %s\n", r.SourceID, r.Error)
+			case r.NotChanged:
+				fmt.Printf("%-16s not modified\n", r.SourceID)
+			default:
+				fmt.Printf("%-16s %d new, %d duplicate(s)\n", r.SourceID, r.New, r.Duplicates)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown planet command %q", args[0])
+}
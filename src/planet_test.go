@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testRSS2 = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/"><channel><title>Alice's Blog</title>
+<item><title>First</title><link>https://alice.example.com/first</link><guid>alice-1</guid>
+<pubDate>Mon, 02 Jun 2025 10:00:00 +0000</pubDate><dc:creator>Alice</dc:creator><description>&lt;p&gt;Hello &lt;b&gt;world&lt;/b&gt;&lt;/p&gt;</description></item>
+<item><title>Second</title><link>https://alice.example.com/second</link><pubDate>Tue, 03 Jun 2025 10:00:00 +0000</pubDate></item>
+</channel></rss>`
+
+const testRSS1 = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel rdf:about="https://bob.example.com/"><title>Bob's Notes</title></channel>
+<item rdf:about="https://bob.example.com/notes/1"><title>Note one</title><link>https://bob.example.com/notes/1</link><dc:date>2025-06-04T08:00:00Z</dc:date><dc:creator>Bob</dc:creator></item>
+</rdf:RDF>`
+
+const testAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Carol</title>
+<entry><title>Atom entry</title><id>tag:carol.example.com,2025:1</id>
+<link rel="alternate" href="https://carol.example.com/posts/1"/><link rel="edit" href="https://carol.example.com/edit/1"/>
+<published>2025-06-05T09:00:00Z</published><author><name>Carol</name></author><summary>Short summary</summary></entry>
+</feed>`
+
+// feedStandIn 提供固定内容的订阅，支持 ETag 条件请求并记录收到的请求头
+type feedStandIn struct {
+	mu          sync.Mutex
+	body        string
+	etag        string
+	requests    int
+	conditional int
+}
+
+func (s *feedStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.etag != "" {
+		if r.Header.Get("If-None-Match") == s.etag {
+			s.conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", s.etag)
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write([]byte(s.body))
+}
+
+func newFeedStandIn(t *testing.T, body, etag string) (*feedStandIn, string) {
+	t.Helper()
+	config.Planet.AllowPrivate = true
+	standIn := &feedStandIn{body: body, etag: etag}
+	srv := httptest.NewServer(standIn)
+	t.Cleanup(srv.Close)
+	return standIn, srv.URL
+}
+
+func TestPlanetParsesFeedFormats(t *testing.T) {
+	cases := []struct {
+		name, body, title string
+		want              []PlanetEntry
+	}{
+		{"rss2", testRSS2, "Alice's Blog", []PlanetEntry{
+			{GUID: "alice-1", Title: "First", Link: "https://alice.example.com/first", Author: "Alice", Summary: "Hello world"},
+			{GUID: "https://alice.example.com/second", Title: "Second", Link: "https://alice.example.com/second"},
+		}},
+		{"rss1", testRSS1, "Bob's Notes", []PlanetEntry{
+			{GUID: "https://bob.example.com/notes/1", Title: "Note one", Link: "https://bob.example.com/notes/1", Author: "Bob"},
+		}},
+		{"atom", testAtom, "Carol", []PlanetEntry{
+			{GUID: "tag:carol.example.com,2025:1", Title: "Atom entry", Link: "https://carol.example.com/posts/1", Author: "Carol", Summary: "Short summary"},
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, feedURL := newFeedStandIn(t, c.body, "")
+			src := &PlanetSource{ID: c.name, URL: feedURL, Mode: "stream"}
+			entries, result := fetchPlanetSource(context.Background(), src, nil)
+			if result.Error != "" {
+				t.Fatal(result.Error)
+			}
+			if len(entries) != len(c.want) {
+				t.Fatalf("got %d entries, want %d", len(entries), len(c.want))
+			}
+			for i, want := range c.want {
+				got := entries[i]
+				if got.GUID != want.GUID || got.Title != want.Title || got.Link != want.Link || got.Author != want.Author || strings.TrimSpace(got.Summary) != want.Summary {
+					t.Errorf("entry %d = %+v, want %+v", i, got, want)
+				}
+				if got.SourceTitle != c.title || got.Published.IsZero() {
+					t.Errorf("entry %d: source title %q, published %v", i, got.SourceTitle, got.Published)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanetConditionalGet(t *testing.T) {
+	standIn, feedURL := newFeedStandIn(t, testRSS2, `"v1"`)
+	src := &PlanetSource{ID: "alice", URL: feedURL, Mode: "stream"}
+
+	entries, result := fetchPlanetSource(context.Background(), src, nil)
+	if result.New != 2 || src.ETag != `"v1"` {
+		t.Fatalf("first fetch: %+v, etag %q", result, src.ETag)
+	}
+	entries, result = fetchPlanetSource(context.Background(), src, entries)
+	if !result.NotChanged || result.Status != http.StatusNotModified || result.New != 0 || len(entries) != 2 {
+		t.Errorf("second fetch: %+v", result)
+	}
+	if standIn.conditional != 1 {
+		t.Errorf("server saw %d conditional request(s), want 1", standIn.conditional)
+	}
+
+	// 内容变化后返回新的 ETag
+	standIn.mu.Lock()
+	standIn.etag = `"v2"`
+	standIn.mu.Unlock()
+	_, result = fetchPlanetSource(context.Background(), src, entries)
+	if result.NotChanged || result.New != 0 || result.Duplicates != 2 || src.ETag != `"v2"` {
+		t.Errorf("third fetch: %+v, etag %q", result, src.ETag)
+	}
+}
+
+func TestPlanetDeduplicates(t *testing.T) {
+	_, aliceURL := newFeedStandIn(t, testRSS2, "")
+	alice := &PlanetSource{ID: "alice", URL: aliceURL, Mode: "stream"}
+	entries, result := fetchPlanetSource(context.Background(), alice, nil)
+	if result.New != 2 {
+		t.Fatalf("first fetch: %+v", result)
+	}
+
+	// 同一来源再次抓取：按GUID去重
+	entries, result = fetchPlanetSource(context.Background(), alice, entries)
+	if result.New != 0 || result.Duplicates != 2 {
+		t.Errorf("same source: %+v", result)
+	}
+
+	// 另一个来源转载了同一篇文章（带跟踪参数和末尾斜杠）：按链接去重
+	repost := strings.Replace(testAtom, "https://carol.example.com/posts/1", "https://Alice.example.com/first/?utm_source=planet#top", 1)
+	_, carolURL := newFeedStandIn(t, repost, "")
+	carol := &PlanetSource{ID: "carol", URL: carolURL, Mode: "stream"}
+	entries, result = fetchPlanetSource(context.Background(), carol, entries)
+	if result.New != 0 || result.Duplicates != 1 || len(entries) != 2 {
+		t.Errorf("cross source: %+v, %d entries", result, len(entries))
+	}
+}
+
+func TestPlanetLinkPostEscapesLink(t *testing.T) {
+	feed := strings.Replace(testAtom, "https://carol.example.com/posts/1", "https://carol.example.com/posts/a (draft)", 1)
+	_, feedURL := newFeedStandIn(t, feed, "")
+	src := &PlanetSource{ID: "carol-links", URL: feedURL, Mode: "link_posts"}
+
+	entries, result := fetchPlanetSource(context.Background(), src, nil)
+	if result.New != 1 || entries[0].BlogID == "" {
+		t.Fatalf("link post not imported: %+v", result)
+	}
+	blog, err := LoadBlog(entries[0].BlogID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(blog.Content, "(https://carol.example.com/posts/a%20%28draft%29)") {
+		t.Errorf("link not escaped in %q", blog.Content)
+	}
+	if html := renderBlogHTML(blog); !strings.Contains(html, `href="https://carol.example.com/posts/a%20%28draft%29"`) {
+		t.Errorf("rendered link is broken: %s", html)
+	}
+}
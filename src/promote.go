@@ -0,0 +1,733 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 站内媒体文件目录，通过 /media/ 访问
+const mediaDir = "data/media"
+
+// 推送包格式版本
+const promotionFormat = "blog-promotion/1"
+
+// 已推送博客的记录（目标站），用于ID映射和冲突检测
+const promotionFile = "data/promotions.json"
+
+func init() {
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		log.Fatalf("Failed to create media directory: %v", err)
+	}
+}
+
+// promotionBundle 从预发布站导出、在正式站应用的推送包
+type promotionBundle struct {
+	Format     string          `json:"format"`      // 格式版本
+	Source     string          `json:"source"`      // 来源站点（base_url），与源ID一起标识博客
+	ExportedAt time.Time       `json:"exported_at"` // 导出时间
+	Blogs      []*Blog         `json:"blogs"`       // 博客（源站ID）
+	Authors    []Author        `json:"authors"`     // 博客引用的作者
+	Media      []promotedMedia `json:"media"`       // 博客引用的站内媒体文件
+}
+
+// promotedMedia 推送包中的媒体文件
+type promotedMedia struct {
+	Path   string `json:"path"`   // 站内路径，如 /media/2024/cover.png
+	SHA256 string `json:"sha256"` // 内容摘要
+	Data   []byte `json:"data"`   // 文件内容（base64）
+}
+
+// promotionRecord 源站博客与目标站博客的对应关系
+type promotionRecord struct {
+	Source        string    `json:"source"`         // 来源站点
+	SourceID      BlogID    `json:"source_id"`      // 源站博客ID
+	TargetID      BlogID    `json:"target_id"`      // 本站博客ID
+	TargetVersion int       `json:"target_version"` // 上次应用后本站的版本号，变化说明正式站上被修改过
+	AppliedAt     time.Time `json:"applied_at"`
+}
+
+func loadPromotionRecords() ([]promotionRecord, error) {
+	data, err := os.ReadFile(promotionFile)
+	if os.IsNotExist(err) {
+		return []promotionRecord{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read promotion records: %w", err)
+	}
+	var records []promotionRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal promotion records: %w", err)
+	}
+	return records, nil
+}
+
+func savePromotionRecords(records []promotionRecord) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal promotion records: %w", err)
+	}
+	if err := os.WriteFile(promotionFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write promotion records: %w", err)
+	}
+	return nil
+}
+
+var mediaRef = regexp.MustCompile(`/media/[^\s()"'<>\]]+`)
+
+// 博客引用的站内媒体路径（正文、内容块和链接预览）
+func blogMediaRefs(b *Blog) []string {
+	texts := []string{b.Content}
+	for _, block := range b.Blocks {
+		texts = append(texts, block.URL)
+	}
+	if b.LinkPreview != nil {
+		texts = append(texts, b.LinkPreview.Image)
+	}
+	var refs []string
+	for _, text := range texts {
+		for _, ref := range mediaRef.FindAllString(text, -1) {
+			if _, err := mediaFilePath(ref); err == nil {
+				refs = append(refs, path.Clean(ref))
+			}
+		}
+	}
+	return refs
+}
+
+// 站内媒体路径对应的文件，拒绝目录穿越
+func mediaFilePath(ref string) (string, error) {
+	clean := path.Clean(ref)
+	rel := strings.TrimPrefix(clean, "/media/")
+	if rel == clean || rel == "" || strings.HasPrefix(rel, "..") || strings.Contains(rel, "/../") {
+		return "", fmt.Errorf("invalid media path %q", ref)
+	}
+	return filepath.Join(mediaDir, filepath.FromSlash(rel)), nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// 导出博客及其作者和媒体文件
+func exportPromotion(ids []BlogID) (*promotionBundle, error) {
+	bundle := &promotionBundle{Format: promotionFormat, Source: config.BaseURL, ExportedAt: time.Now().UTC(), Blogs: []*Blog{}, Authors: []Author{}, Media: []promotedMedia{}}
+	authors := make(map[int]bool)
+	media := make(map[string]bool)
+	for _, id := range ids {
+		blog, err := LoadBlog(id)
+		if err != nil {
+			return nil, fmt.Errorf("blog %s: %w", id, err)
+		}
+		// 浏览次数和签名属于各自站点
+		blog.ViewCount, blog.Signature = 0, nil
+		bundle.Blogs = append(bundle.Blogs, blog)
+
+		if blog.AuthorID != 0 && !authors[blog.AuthorID] {
+			authors[blog.AuthorID] = true
+			if author, err := LoadAuthor(blog.AuthorID); err == nil {
+				bundle.Authors = append(bundle.Authors, *author)
+			}
+		}
+		for _, ref := range blogMediaRefs(blog) {
+			if media[ref] {
+				continue
+			}
+			media[ref] = true
+			file, _ := mediaFilePath(ref)
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("blog %s references missing media %s", id, ref)
+			}
+			bundle.Media = append(bundle.Media, promotedMedia{Path: ref, SHA256: sha256Hex(data), Data: data})
+		}
+	}
+	return bundle, nil
+}
+
+// promotionReport 应用（或预演）推送包的结果
+type promotionReport struct {
+	DryRun    bool                    `json:"dry_run"`
+	Applied   bool                    `json:"applied"`
+	Conflicts int                     `json:"conflicts"`
+	Blogs     []promotionBlogChange   `json:"blogs"`
+	Authors   []promotionAuthorChange `json:"authors"`
+	Media     []promotionMediaChange  `json:"media"`
+}
+
+// promotionBlogChange 单篇博客的变更
+type promotionBlogChange struct {
+	SourceID BlogID   `json:"source_id"`
+	TargetID BlogID   `json:"target_id"`
+	Title    string   `json:"title"`
+	Action   string   `json:"action"`           // create、update、unchanged 或 conflict
+	Reason   string   `json:"reason,omitempty"` // 冲突原因
+	Diff     []string `json:"diff,omitempty"`   // 与本站当前版本的差异
+}
+
+// promotionAuthorChange 作者映射
+type promotionAuthorChange struct {
+	SourceID int    `json:"source_id"`
+	TargetID int    `json:"target_id"`
+	Name     string `json:"name"`
+	Action   string `json:"action"` // existing 或 create
+}
+
+// promotionMediaChange 媒体文件的变更
+type promotionMediaChange struct {
+	Path   string `json:"path"`
+	Action string `json:"action"` // create、unchanged 或 conflict（force 时为 overwrite）
+}
+
+// 推送时比较的博客字段
+type promotedFields struct {
+	Title         string
+	AuthorID      int
+	Content       string
+	Tags          []string
+	LinkURL       string
+	IsPublished   bool
+	Lang          string
+	TranslationOf BlogID
+}
+
+func blogFields(b *Blog) promotedFields {
+	return promotedFields{
+		Title: b.Title, AuthorID: b.AuthorID, Content: b.Content, Tags: b.Tags,
+		LinkURL: b.LinkURL, IsPublished: b.IsPublished, Lang: b.Lang, TranslationOf: b.TranslationOf,
+	}
+}
+
+// 字段差异：单行字段显示新旧值，正文按行比较
+func blogDiff(old, new promotedFields) []string {
+	var diff []string
+	field := func(name string, a, b interface{}) {
+		as, bs := fmt.Sprint(a), fmt.Sprint(b)
+		if as != bs {
+			diff = append(diff, fmt.Sprintf("%s: %s -> %s", name, as, bs))
+		}
+	}
+	field("title", strconv.Quote(old.Title), strconv.Quote(new.Title))
+	field("author_id", old.AuthorID, new.AuthorID)
+	field("tags", old.Tags, new.Tags)
+	field("link_url", old.LinkURL, new.LinkURL)
+	field("is_published", old.IsPublished, new.IsPublished)
+	field("lang", old.Lang, new.Lang)
+	field("translation_of", old.TranslationOf, new.TranslationOf)
+	if old.Content != new.Content {
+		diff = append(diff, "content:")
+		for _, line := range diffLines(splitLines(old.Content), splitLines(new.Content)) {
+			diff = append(diff, "  "+line)
+		}
+	}
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// 按行比较（最长公共子序列），只返回删除（-）和新增（+）的行
+func diffLines(a, b []string) []string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var out []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	return out
+}
+
+// 为新博客预分配ID：递增整数方案从现有最大ID之后连续分配
+func allocateBlogIDs(n int) []BlogID {
+	ids := make([]BlogID, 0, n)
+	if config.IDScheme == "ulid" {
+		for len(ids) < n {
+			ids = append(ids, newBlogID())
+		}
+		return ids
+	}
+	next, _ := strconv.Atoi(string(generateNewBlogID()))
+	for ; len(ids) < n; next++ {
+		ids = append(ids, BlogID(strconv.Itoa(next)))
+	}
+	return ids
+}
+
+var blogPathRef = regexp.MustCompile(`((?:https?:)?//[^/\s"'()<>\]]+)?(/(?:api/)?blogs/)([0-9A-Za-z_-]+)`)
+
+// 将正文中指向源站博客的链接改写为本站ID：只改写站内相对链接和主机为源站的绝对链接
+func remapBlogLinks(text, source string, idMap map[BlogID]BlogID) string {
+	sourceHost := ""
+	if u, err := url.Parse(source); err == nil {
+		sourceHost = u.Host
+	}
+	return blogPathRef.ReplaceAllStringFunc(text, func(m string) string {
+		parts := blogPathRef.FindStringSubmatch(m)
+		if parts[1] != "" {
+			u, err := url.Parse(parts[1])
+			if err != nil || sourceHost == "" || !strings.EqualFold(u.Host, sourceHost) {
+				return m
+			}
+		}
+		if target, ok := idMap[BlogID(parts[3])]; ok {
+			return parts[1] + parts[2] + string(target)
+		}
+		return m
+	})
+}
+
+// 应用推送包：映射作者和博客ID，检测冲突（正式站上修改过的博客、内容不同的同名媒体文件）。
+// dryRun 时只返回变更和差异；存在冲突且未指定 force 时不做任何修改。
+func applyPromotion(bundle *promotionBundle, dryRun, force bool) (*promotionReport, error) {
+	if bundle.Format != promotionFormat {
+		return nil, fmt.Errorf("unsupported bundle format %q", bundle.Format)
+	}
+	if bundle.Source == "" {
+		return nil, fmt.Errorf("bundle has no source")
+	}
+	report := &promotionReport{DryRun: dryRun, Blogs: []promotionBlogChange{}, Authors: []promotionAuthorChange{}, Media: []promotionMediaChange{}}
+
+	// 作者：同ID同名直接使用，否则按名称匹配本站作者，都没有时新建
+	authorMap := make(map[int]int)
+	var newAuthors []Author
+	nextAuthorID, err := maxAuthorID()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]int)
+	entries, _ := os.ReadDir(authorDir)
+	for _, e := range entries {
+		if id, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".json")); err == nil {
+			if a, err := LoadAuthor(id); err == nil {
+				byName[a.Name] = a.ID
+			}
+		}
+	}
+	for _, a := range bundle.Authors {
+		change := promotionAuthorChange{SourceID: a.ID, Name: a.Name, Action: "existing"}
+		existing, err := LoadAuthor(a.ID)
+		switch id, ok := byName[a.Name]; {
+		case err == nil && existing.Name == a.Name:
+			change.TargetID = a.ID
+		case ok:
+			change.TargetID = id
+		default:
+			// 优先沿用源站ID，已被其他作者占用时分配新ID
+			change.TargetID, change.Action = a.ID, "create"
+			if err == nil {
+				change.TargetID = nextAuthorID + 1
+			}
+			if change.TargetID > nextAuthorID {
+				nextAuthorID = change.TargetID
+			}
+			byName[a.Name] = change.TargetID
+			newAuthors = append(newAuthors, Author{ID: change.TargetID, Name: a.Name, Timezone: a.Timezone})
+		}
+		authorMap[a.ID] = change.TargetID
+		report.Authors = append(report.Authors, change)
+	}
+
+	// 媒体：同路径内容不同视为冲突
+	for _, m := range bundle.Media {
+		file, err := mediaFilePath(m.Path)
+		if err != nil {
+			return nil, err
+		}
+		if sha256Hex(m.Data) != m.SHA256 {
+			return nil, fmt.Errorf("media %s is corrupted (checksum mismatch)", m.Path)
+		}
+		change := promotionMediaChange{Path: m.Path, Action: "create"}
+		if existing, err := os.ReadFile(file); err == nil {
+			switch {
+			case bytes.Equal(existing, m.Data):
+				change.Action = "unchanged"
+			case force:
+				change.Action = "overwrite"
+			default:
+				change.Action = "conflict"
+				report.Conflicts++
+			}
+		}
+		report.Media = append(report.Media, change)
+	}
+
+	// 博客：按来源记录映射ID，本站版本号与上次应用后不同说明正式站上被修改过
+	records, err := loadPromotionRecords()
+	if err != nil {
+		return nil, err
+	}
+	recordIndex := make(map[BlogID]int)
+	idMap := make(map[BlogID]BlogID)
+	for i, rec := range records {
+		if rec.Source == bundle.Source {
+			recordIndex[rec.SourceID] = i
+			idMap[rec.SourceID] = rec.TargetID
+		}
+	}
+	var newCount int
+	for _, b := range bundle.Blogs {
+		if i, ok := recordIndex[b.ID]; ok {
+			if _, err := LoadBlog(records[i].TargetID); err == nil {
+				continue
+			}
+		}
+		newCount++
+	}
+	newIDs := allocateBlogIDs(newCount)
+	current := make(map[BlogID]*Blog)
+	for _, b := range bundle.Blogs {
+		i, ok := recordIndex[b.ID]
+		if ok {
+			if existing, err := LoadBlog(records[i].TargetID); err == nil {
+				current[b.ID] = existing
+				continue
+			}
+		}
+		idMap[b.ID], newIDs = newIDs[0], newIDs[1:]
+	}
+
+	var pending []*Blog
+	var pendingSources []BlogID
+	for _, b := range bundle.Blogs {
+		target := *b
+		target.ID = idMap[b.ID]
+		target.Content = remapBlogLinks(b.Content, bundle.Source, idMap)
+		target.Blocks = append([]Block(nil), b.Blocks...)
+		for i := range target.Blocks {
+			block := &target.Blocks[i]
+			block.Text = remapBlogLinks(block.Text, bundle.Source, idMap)
+			block.URL = remapBlogLinks(block.URL, bundle.Source, idMap)
+			block.Items = append([]string(nil), block.Items...)
+			for j := range block.Items {
+				block.Items[j] = remapBlogLinks(block.Items[j], bundle.Source, idMap)
+			}
+		}
+		if id, ok := authorMap[b.AuthorID]; ok {
+			target.AuthorID = id
+		}
+		if b.TranslationOf != "" {
+			target.TranslationOf = idMap[b.TranslationOf] // 原文未推送过时不保留译文关系
+		}
+		fields := blogFields(&target)
+
+		change := promotionBlogChange{SourceID: b.ID, TargetID: target.ID, Title: b.Title, Action: "create"}
+		existing := current[b.ID]
+		if existing == nil {
+			change.Diff = blogDiff(promotedFields{}, fields)
+		} else {
+			rec := records[recordIndex[b.ID]]
+			change.Diff = blogDiff(blogFields(existing), fields)
+			switch {
+			case existing.Version != rec.TargetVersion && !force:
+				change.Action = "conflict"
+				change.Reason = fmt.Sprintf("modified here since the last promotion (version %d, promoted as version %d)", existing.Version, rec.TargetVersion)
+				report.Conflicts++
+			case len(change.Diff) == 0:
+				change.Action = "unchanged"
+			default:
+				change.Action = "update"
+			}
+			target.CreatedTime, target.ViewCount = existing.CreatedTime, existing.ViewCount
+		}
+		report.Blogs = append(report.Blogs, change)
+		if change.Action == "create" || change.Action == "update" {
+			pending = append(pending, &target)
+			pendingSources = append(pendingSources, b.ID)
+		}
+	}
+
+	if dryRun || report.Conflicts > 0 {
+		return report, nil
+	}
+
+	for i := range newAuthors {
+		if err := newAuthors[i].Save(); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range bundle.Media {
+		file, _ := mediaFilePath(m.Path)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create media directory: %w", err)
+		}
+		if err := os.WriteFile(file, m.Data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write media file: %w", err)
+		}
+	}
+	now := time.Now().UTC()
+	for i, b := range pending {
+		sourceID := pendingSources[i]
+		if err := SaveBlog(b); err != nil {
+			return nil, fmt.Errorf("failed to save blog %s: %w", b.ID, err)
+		}
+		rec := promotionRecord{Source: bundle.Source, SourceID: sourceID, TargetID: b.ID, TargetVersion: b.Version, AppliedAt: now}
+		if i, ok := recordIndex[sourceID]; ok {
+			records[i] = rec
+		} else {
+			recordIndex[sourceID] = len(records)
+			records = append(records, rec)
+		}
+	}
+	if err := savePromotionRecords(records); err != nil {
+		return nil, err
+	}
+	report.Applied = true
+	return report, nil
+}
+
+// 推送接口（需要管理员密钥）：
+// POST /api/admin/promote/export {"ids": [...]} 导出推送包；
+// POST /api/admin/promote/apply?dry_run=1&force=1 应用推送包，存在冲突时返回409
+func promoteHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch strings.TrimPrefix(r.URL.Path, "/api/admin/promote/") {
+	case "export":
+		var req struct {
+			IDs []BlogID `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.IDs) == 0 {
+			sendResponse(w, false, "", nil, "Request must list blog ids", http.StatusBadRequest)
+			return
+		}
+		bundle, err := exportPromotion(req.IDs)
+		if err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendResponse(w, true, "Bundle exported successfully", bundle, "", http.StatusOK)
+
+	case "apply":
+		var bundle promotionBundle
+		if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+			sendResponse(w, false, "", nil, "Invalid bundle", http.StatusBadRequest)
+			return
+		}
+		query := r.URL.Query()
+		dryRun, _ := strconv.ParseBool(query.Get("dry_run"))
+		force, _ := strconv.ParseBool(query.Get("force"))
+		report, err := applyPromotion(&bundle, dryRun, force)
+		if err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if report.Conflicts > 0 && !dryRun {
+			sendResponse(w, false, "", report, fmt.Sprintf("%d conflict(s) found; nothing was applied", report.Conflicts), http.StatusConflict)
+			return
+		}
+		sendResponse(w, true, "Bundle applied successfully", report, "", http.StatusOK)
+
+	default:
+		sendResponse(w, false, "", nil, "Not found", http.StatusNotFound)
+	}
+}
+
+// 站内媒体文件：GET /media/...
+func mediaHandler(w http.ResponseWriter, r *http.Request) {
+	file, err := mediaFilePath(r.URL.Path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	// 只提供文件，不列出目录内容
+	if info, err := os.Stat(file); err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, file)
+}
+
+// 打印应用结果
+func printPromotionReport(report *promotionReport) {
+	for _, a := range report.Authors {
+		fmt.Printf("author %d -> %d  %-9s %s\n", a.SourceID, a.TargetID, a.Action, a.Name)
+	}
+	for _, m := range report.Media {
+		fmt.Printf("media  %-9s %s\n", m.Action, m.Path)
+	}
+	for _, b := range report.Blogs {
+		fmt.Printf("blog   %s -> %s  %-9s %s\n", b.SourceID, b.TargetID, b.Action, b.Title)
+		if b.Reason != "" {
+			fmt.Printf("       %s\n", b.Reason)
+		}
+		if report.DryRun {
+			for _, line := range b.Diff {
+				fmt.Printf("       %s\n", line)
+			}
+		}
+	}
+}
+
+// 推送博客到其他站点：blog promote export|apply|push
+func runPromote(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: blog promote export|apply|push")
+	}
+	parseIDs := func(values []string) ([]BlogID, error) {
+		var ids []BlogID
+		for _, v := range values {
+			id, err := parseBlogID(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", v, err)
+			}
+			ids = append(ids, id)
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no blog IDs given")
+		}
+		return ids, nil
+	}
+
+	switch args[0] {
+	case "export":
+		fs := flag.NewFlagSet("promote export", flag.ExitOnError)
+		out := fs.String("o", "", "write the bundle to this file instead of stdout")
+		fs.Parse(args[1:])
+		ids, err := parseIDs(fs.Args())
+		if err != nil {
+			return err
+		}
+		bundle, err := exportPromotion(ids)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			return err
+		}
+		if *out == "" {
+			_, err = os.Stdout.Write(append(data, '\n'))
+			return err
+		}
+		return os.WriteFile(*out, data, 0644)
+
+	case "apply":
+		fs := flag.NewFlagSet("promote apply", flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "show the changes and diffs without applying them")
+		force := fs.Bool("force", false, "overwrite posts and media changed on this instance")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: blog promote apply [-dry-run] [-force] <bundle.json>")
+		}
+		data, err := os.ReadFile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		var bundle promotionBundle
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			return fmt.Errorf("invalid bundle: %w", err)
+		}
+		report, err := applyPromotion(&bundle, *dryRun, *force)
+		if err != nil {
+			return err
+		}
+		printPromotionReport(report)
+		if report.Conflicts > 0 && !*dryRun {
+			return fmt.Errorf("%d conflict(s) found; nothing was applied (use -force to overwrite)", report.Conflicts)
+		}
+		return nil
+
+	case "push":
+		fs := flag.NewFlagSet("promote push", flag.ExitOnError)
+		target := fs.String("to", "", "base URL of the target instance, e.g. https://blog.example.com")
+		key := fs.String("key", os.Getenv("BLOG_PROMOTE_KEY"), "admin API key of the target instance (or BLOG_PROMOTE_KEY)")
+		dryRun := fs.Bool("dry-run", false, "show the changes and diffs without applying them")
+		force := fs.Bool("force", false, "overwrite posts and media changed on the target")
+		fs.Parse(args[1:])
+		if *target == "" || *key == "" {
+			return fmt.Errorf("usage: blog promote push -to <url> -key <admin key> [-dry-run] [-force] <id>...")
+		}
+		ids, err := parseIDs(fs.Args())
+		if err != nil {
+			return err
+		}
+		bundle, err := exportPromotion(ids)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(bundle)
+		if err != nil {
+			return err
+		}
+		endpoint := fmt.Sprintf("%s/api/admin/promote/apply?dry_run=%t&force=%t", strings.TrimSuffix(*target, "/"), *dryRun, *force)
+		req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+*key)
+		// 推送包含媒体文件，超时比其他出站请求长
+		client := &http.Client{Timeout: 2 * time.Minute}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+
+		var result struct {
+			ApiResponse
+			Data *promotionReport `json:"data"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return fmt.Errorf("unexpected response (%s): %s", resp.Status, body)
+		}
+		if result.Data != nil {
+			printPromotionReport(result.Data)
+		}
+		if !result.Success {
+			return fmt.Errorf("%s", result.Error)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown promote command %q", args[0])
+}
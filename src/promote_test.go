@@ -0,0 +1,198 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 切换到一个新的空站点目录，测试结束后返回原目录
+func useTestSite(t *testing.T) {
+	t.Helper()
+	prev, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(prev) })
+	if err := makeTestDataDirs(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func saveTestBlog(t *testing.T, b *Blog) {
+	t.Helper()
+	if err := SaveBlog(b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findBlogChange(report *promotionReport, source BlogID) promotionBlogChange {
+	for _, c := range report.Blogs {
+		if c.SourceID == source {
+			return c
+		}
+	}
+	return promotionBlogChange{}
+}
+
+func TestPromotionRoundTrip(t *testing.T) {
+	prevBaseURL := config.BaseURL
+	t.Cleanup(func() { config.BaseURL = prevBaseURL })
+
+	// 预发布站：两篇互相链接的博客和一张图片
+	useTestSite(t)
+	stage, _ := os.Getwd()
+	config.BaseURL = "https://stage.example.com"
+	if err := (&Author{ID: 1, Name: "Alice"}).Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(mediaDir, "img"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, "img", "a.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saveTestBlog(t, &Blog{ID: "1", Title: "Launch", AuthorID: 1, IsPublished: true, Content: "![a](/media/img/a.png)\n" +
+		"[next](/blogs/2) [abs](https://stage.example.com/blogs/2) [other](https://other.example.com/blogs/2)",
+		Blocks: []Block{
+			{Type: "embed", URL: "https://stage.example.com/blogs/2"},
+			{Type: "list", Items: []string{"[part two](/blogs/2)"}},
+		}})
+	saveTestBlog(t, &Blog{ID: "2", Title: "Part two", AuthorID: 1, IsPublished: true, Content: "second"})
+	bundle, err := exportPromotion([]BlogID{"1", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Blogs) != 2 || len(bundle.Authors) != 1 || len(bundle.Media) != 1 {
+		t.Fatalf("bundle has %d blogs, %d authors, %d media", len(bundle.Blogs), len(bundle.Authors), len(bundle.Media))
+	}
+
+	// 正式站：ID 1 和 2 已被占用
+	useTestSite(t)
+	prod, _ := os.Getwd()
+	config.BaseURL = "https://www.example.com"
+	saveTestBlog(t, &Blog{ID: "1", Title: "Existing one", Content: "one"})
+	saveTestBlog(t, &Blog{ID: "2", Title: "Existing two", Content: "two"})
+
+	report, err := applyPromotion(bundle, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	launch := findBlogChange(report, "1")
+	if report.Applied || launch.Action != "create" || launch.TargetID != "3" || len(launch.Diff) == 0 {
+		t.Fatalf("dry run: applied %v, change %+v", report.Applied, launch)
+	}
+	if _, err := LoadBlog("3"); err == nil {
+		t.Fatal("dry run saved a blog")
+	}
+
+	report, err = applyPromotion(bundle, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Applied || report.Conflicts != 0 {
+		t.Fatalf("apply: %+v", report)
+	}
+	b, err := LoadBlog("3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[next](/blogs/4)", "[abs](https://stage.example.com/blogs/4)", "[other](https://other.example.com/blogs/2)"} {
+		if !strings.Contains(b.Content, want) {
+			t.Errorf("content %q does not contain %q", b.Content, want)
+		}
+	}
+	if len(b.Blocks) != 2 || b.Blocks[0].URL != "https://stage.example.com/blogs/4" || b.Blocks[1].Items[0] != "[part two](/blogs/4)" {
+		t.Errorf("block links not remapped: %+v", b.Blocks)
+	}
+	if bundle.Blogs[0].Blocks[1].Items[0] != "[part two](/blogs/2)" {
+		t.Errorf("remapping modified the bundle: %+v", bundle.Blogs[0].Blocks)
+	}
+	if existing, _ := LoadBlog("1"); existing.Title != "Existing one" {
+		t.Errorf("existing blog 1 was overwritten: %q", existing.Title)
+	}
+	if data, err := os.ReadFile(filepath.Join(mediaDir, "img", "a.png")); err != nil || string(data) != "png" {
+		t.Errorf("media not copied: %q, %v", data, err)
+	}
+
+	// 再次应用相同的推送包：无变化
+	report, err = applyPromotion(bundle, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := findBlogChange(report, "1"); c.Action != "unchanged" || c.TargetID != "3" {
+		t.Errorf("reapply: %+v", c)
+	}
+
+	// 正式站上修改过的博客：冲突，不做任何修改
+	b.Title = "Edited on production"
+	saveTestBlog(t, b)
+	os.Chdir(stage)
+	config.BaseURL = "https://stage.example.com"
+	updated, _ := LoadBlog("1")
+	updated.Content += "\nmore"
+	saveTestBlog(t, updated)
+	bundle, err = exportPromotion([]BlogID{"1", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Chdir(prod)
+	config.BaseURL = "https://www.example.com"
+
+	report, err = applyPromotion(bundle, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := findBlogChange(report, "1")
+	if report.Conflicts != 1 || c.Action != "conflict" || !strings.Contains(strings.Join(c.Diff, "\n"), "more") {
+		t.Errorf("dry run with conflict: %d conflict(s), %+v", report.Conflicts, c)
+	}
+	report, err = applyPromotion(bundle, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Applied {
+		t.Error("bundle with conflicts was applied")
+	}
+	if current, _ := LoadBlog("3"); current.Title != "Edited on production" {
+		t.Errorf("conflicting blog was overwritten: %q", current.Title)
+	}
+
+	report, err = applyPromotion(bundle, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current, _ := LoadBlog("3"); !report.Applied || current.Title != "Launch" || !strings.Contains(current.Content, "more") {
+		t.Errorf("forced apply: applied %v, blog %+v", report.Applied, current)
+	}
+}
+
+func TestMediaHandlerDoesNotListDirectories(t *testing.T) {
+	useTestSite(t)
+	if err := os.MkdirAll(filepath.Join(mediaDir, "img"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, "img", "a.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]int{
+		"/media/img/a.png": http.StatusOK,
+		"/media/img/":      http.StatusNotFound,
+		"/media/img":       http.StatusNotFound,
+		"/media/":          http.StatusNotFound,
+		"/media/missing":   http.StatusNotFound,
+	}
+	for path, want := range cases {
+		rec := httptest.NewRecorder()
+		mediaHandler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
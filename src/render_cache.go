@@ -0,0 +1,279 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 渲染函数：返回页面内容及其依赖（如 blog:1、tag:golang、index）
+type renderFunc func() ([]byte, []string, error)
+
+// 缓存命中状态
+const (
+	cacheHit   = "HIT"
+	cacheStale = "STALE"
+	cacheMiss  = "MISS"
+)
+
+// cacheEntry 缓存条目
+type cacheEntry struct {
+	key        string
+	body       []byte // 溢出到磁盘后为nil
+	spillPath  string // 磁盘溢出文件路径
+	size       int64
+	deps       []string
+	renderedAt time.Time
+	stale      bool          // 依赖已失效，等待后台重新渲染
+	memElem    *list.Element // 内存LRU位置
+	diskElem   *list.Element // 磁盘LRU位置
+}
+
+// renderCache 按路由、版本和主题缓存渲染结果，支持依赖失效与过期后台刷新
+type renderCache struct {
+	mu         sync.Mutex
+	entries    map[string]*cacheEntry
+	deps       map[string]map[string]struct{} // 依赖 -> 缓存键
+	memLRU     *list.List
+	diskLRU    *list.List
+	memBytes   int64
+	diskBytes  int64
+	refreshing map[string]bool
+	cfg        RenderCacheConfig
+
+	// 失效代数：渲染开始后依赖又被失效时不写入渲染结果，避免旧内容被当作最新内容
+	gen         uint64
+	invalidated map[string]uint64 // 依赖 -> 最近一次失效时的代数
+}
+
+// 全局页面缓存
+var pageCache *renderCache
+
+func newRenderCache(cfg RenderCacheConfig) *renderCache {
+	if cfg.SpillDir != "" {
+		// 清理上次运行遗留的溢出文件
+		os.RemoveAll(cfg.SpillDir)
+		if err := os.MkdirAll(cfg.SpillDir, 0755); err != nil {
+			log.Printf("Failed to create cache spill directory, spilling disabled: %v", err)
+			cfg.SpillDir = ""
+		}
+	}
+	return &renderCache{
+		entries:     make(map[string]*cacheEntry),
+		deps:        make(map[string]map[string]struct{}),
+		memLRU:      list.New(),
+		diskLRU:     list.New(),
+		refreshing:  make(map[string]bool),
+		cfg:         cfg,
+		invalidated: make(map[string]uint64),
+	}
+}
+
+// 生成缓存键
+func pageCacheKey(route string, version int, theme string) string {
+	return fmt.Sprintf("%s|v%d|%s", route, version, theme)
+}
+
+// Get 获取缓存的页面，未命中或过期太久时同步渲染，轻度过期时返回旧内容并在后台刷新
+func (c *renderCache) Get(key string, render renderFunc) ([]byte, string, error) {
+	if c == nil || c.cfg.Disabled {
+		body, _, err := render()
+		return body, cacheMiss, err
+	}
+
+	fresh := time.Duration(c.cfg.FreshSeconds) * time.Second
+	staleFor := time.Duration(c.cfg.StaleSeconds) * time.Second
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		age := time.Since(e.renderedAt)
+		switch {
+		case !e.stale && age < fresh:
+			if body, err := c.read(e); err == nil {
+				c.mu.Unlock()
+				return body, cacheHit, nil
+			}
+		case age < fresh+staleFor:
+			if body, err := c.read(e); err == nil {
+				if !c.refreshing[key] {
+					c.refreshing[key] = true
+					go c.revalidate(key, render, c.gen)
+				}
+				c.mu.Unlock()
+				return body, cacheStale, nil
+			}
+		}
+	}
+	gen := c.gen
+	c.mu.Unlock()
+
+	body, deps, err := render()
+	if err != nil {
+		return nil, cacheMiss, err
+	}
+	c.store(key, body, deps, gen)
+	return body, cacheMiss, nil
+}
+
+// 后台重新渲染，gen 为开始渲染前的失效代数
+func (c *renderCache) revalidate(key string, render renderFunc, gen uint64) {
+	body, deps, err := render()
+
+	c.mu.Lock()
+	delete(c.refreshing, key)
+	c.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Failed to revalidate cached page %s: %v", key, err)
+		return
+	}
+	c.store(key, body, deps, gen)
+}
+
+// Invalidate 将依赖于指定资源的缓存标记为过期
+func (c *renderCache) Invalidate(deps ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	for _, dep := range deps {
+		c.invalidated[dep] = c.gen
+		for key := range c.deps[dep] {
+			if e, ok := c.entries[key]; ok {
+				e.stale = true
+			}
+		}
+	}
+}
+
+// 写入缓存（调用方不持有锁）；渲染期间（gen 之后）有依赖失效时丢弃结果，保留已标记为过期的条目
+func (c *renderCache) store(key string, body []byte, deps []string, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, dep := range deps {
+		if c.invalidated[dep] > gen {
+			return
+		}
+	}
+
+	if old, ok := c.entries[key]; ok {
+		c.remove(old)
+	}
+
+	e := &cacheEntry{
+		key:        key,
+		body:       body,
+		size:       int64(len(body)),
+		deps:       deps,
+		renderedAt: time.Now(),
+	}
+	e.memElem = c.memLRU.PushFront(e)
+	c.memBytes += e.size
+	c.entries[key] = e
+	for _, dep := range deps {
+		if c.deps[dep] == nil {
+			c.deps[dep] = make(map[string]struct{})
+		}
+		c.deps[dep][key] = struct{}{}
+	}
+
+	c.evict()
+}
+
+// 读取条目内容（持有锁）
+func (c *renderCache) read(e *cacheEntry) ([]byte, error) {
+	if e.memElem != nil {
+		c.memLRU.MoveToFront(e.memElem)
+		return e.body, nil
+	}
+	c.diskLRU.MoveToFront(e.diskElem)
+	body, err := os.ReadFile(e.spillPath)
+	if err != nil {
+		c.remove(e)
+		return nil, err
+	}
+	return body, nil
+}
+
+// 超出内存上限时将最久未用的条目溢出到磁盘或直接淘汰（持有锁）
+func (c *renderCache) evict() {
+	maxMem := int64(c.cfg.MaxMemoryMB) << 20
+	maxDisk := int64(c.cfg.MaxDiskMB) << 20
+
+	for c.memBytes > maxMem && c.memLRU.Len() > 0 {
+		e := c.memLRU.Back().Value.(*cacheEntry)
+		if c.cfg.SpillDir == "" || e.size > maxDisk || !c.spill(e) {
+			c.remove(e)
+		}
+	}
+
+	for c.diskBytes > maxDisk && c.diskLRU.Len() > 0 {
+		c.remove(c.diskLRU.Back().Value.(*cacheEntry))
+	}
+}
+
+// 将条目写入磁盘并释放内存（持有锁）
+func (c *renderCache) spill(e *cacheEntry) bool {
+	sum := sha256.Sum256([]byte(e.key))
+	path := filepath.Join(c.cfg.SpillDir, hex.EncodeToString(sum[:])+".html")
+	if err := os.WriteFile(path, e.body, 0644); err != nil {
+		log.Printf("Failed to spill cached page to disk: %v", err)
+		return false
+	}
+
+	c.memLRU.Remove(e.memElem)
+	c.memBytes -= e.size
+	e.memElem = nil
+	e.body = nil
+	e.spillPath = path
+	e.diskElem = c.diskLRU.PushFront(e)
+	c.diskBytes += e.size
+	return true
+}
+
+// 移除条目（持有锁）
+func (c *renderCache) remove(e *cacheEntry) {
+	if e.memElem != nil {
+		c.memLRU.Remove(e.memElem)
+		c.memBytes -= e.size
+	}
+	if e.diskElem != nil {
+		c.diskLRU.Remove(e.diskElem)
+		c.diskBytes -= e.size
+		os.Remove(e.spillPath)
+	}
+	for _, dep := range e.deps {
+		delete(c.deps[dep], e.key)
+		if len(c.deps[dep]) == 0 {
+			delete(c.deps, dep)
+		}
+	}
+	delete(c.entries, e.key)
+}
+
+// 博客保存后使相关页面失效：文章页、旧/新标签页和首页
+func invalidateBlogPages(old, blog *Blog) {
+	deps := []string{blogDep(blog.ID), "index"}
+	for _, tag := range blog.Tags {
+		deps = append(deps, tagDep(tag))
+	}
+	if old != nil {
+		for _, tag := range old.Tags {
+			deps = append(deps, tagDep(tag))
+		}
+	}
+	pageCache.Invalidate(deps...)
+}
+
+func blogDep(id BlogID) string { return "blog:" + string(id) }
+func tagDep(tag string) string { return "tag:" + tag }
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestRenderCacheKeepsInvalidationDuringRender(t *testing.T) {
+	c := newRenderCache(RenderCacheConfig{MaxMemoryMB: 1, FreshSeconds: 300, StaleSeconds: 3600})
+	version := "old"
+	render := func() ([]byte, []string, error) {
+		body := version
+		// 渲染期间博客被修改
+		if version == "old" {
+			version = "new"
+			c.Invalidate(blogDep("1"))
+		}
+		return []byte(body), []string{blogDep("1")}, nil
+	}
+
+	body, status, err := c.Get("/blogs/1", render)
+	if err != nil || string(body) != "old" || status != cacheMiss {
+		t.Fatalf("first get: %q %s %v", body, status, err)
+	}
+	// 渲染开始后失效的结果不能被缓存为最新内容
+	body, status, err = c.Get("/blogs/1", render)
+	if err != nil || string(body) != "new" || status != cacheMiss {
+		t.Fatalf("second get: %q %s %v", body, status, err)
+	}
+	body, status, _ = c.Get("/blogs/1", render)
+	if string(body) != "new" || status != cacheHit {
+		t.Errorf("third get: %q %s", body, status)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blogRepresentation 博客的一种不带 ApiResponse 包装的表示
+type blogRepresentation struct {
+	ext       string // 扩展名，如 /api/blogs/1.md
+	mediaType string // Accept 协商时匹配的媒体类型
+	render    func(b *Blog) ([]byte, error)
+}
+
+// 支持的表示，按 Accept 中q值相同时的优先顺序排列
+var blogRepresentations = []blogRepresentation{
+	{"md", "text/markdown", func(b *Blog) ([]byte, error) { return marshalFrontMatter(b), nil }},
+	{"txt", "text/plain", func(b *Blog) ([]byte, error) {
+		return []byte(b.Title + "\n\n" + renderBlogText(b) + "\n"), nil
+	}},
+	{"html", "text/html", renderStandaloneHTML},
+	{"json", "application/json", func(b *Blog) ([]byte, error) {
+		data, err := json.MarshalIndent(b, "", "  ")
+		return append(data, '\n'), err
+	}},
+}
+
+var standalonePage = template.Must(template.New("standalone").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Blog.Title}}</title>
+{{if .Canonical}}<link rel="canonical" href="{{.Canonical}}">
+{{end}}</head>
+<body>
+<article>
+<h1>{{.Blog.Title}}</h1>
+<p><time datetime="{{.Created}}">{{.Created}}</time>{{range .Blog.Tags}} #{{.}}{{end}}</p>
+{{.Content}}
+</article>
+</body>
+</html>
+`))
+
+// 独立的HTML文档：只有正文，不含站点布局、菜单和样式
+func renderStandaloneHTML(b *Blog) ([]byte, error) {
+	data := struct {
+		Blog      *Blog
+		Lang      string
+		Created   string
+		Canonical string
+		Content   template.HTML
+	}{
+		Blog:    b,
+		Lang:    firstNonEmpty(b.Lang, config.Language),
+		Created: b.CreatedTime.Format(time.RFC3339),
+		Content: template.HTML(renderBlogHTML(b)),
+	}
+	if b.IsPublished {
+		data.Canonical = absoluteURL("/blogs/" + string(b.ID))
+	}
+	var buf bytes.Buffer
+	if err := standalonePage.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Link 头：列出博客的全部表示
+func blogAlternateLinks(id BlogID) string {
+	links := make([]string, 0, len(blogRepresentations))
+	for _, rep := range blogRepresentations {
+		links = append(links, fmt.Sprintf(`<%s.%s>; rel="alternate"; type="%s"`, blogURL(id), rep.ext, rep.mediaType))
+	}
+	return strings.Join(links, ", ")
+}
+
+// 按 Accept 选择表示：q值最高的 text/markdown、text/plain 或 text/html；
+// application/json、通配符或JSON:API 的q值不低于它们时返回nil，仍使用默认的 ApiResponse 格式
+func negotiateBlogRepresentation(r *http.Request) *blogRepresentation {
+	accept := r.Header.Get("Accept")
+	if accept == "" || wantsJSONAPI(r) {
+		return nil
+	}
+	var best *blogRepresentation
+	bestQ, defaultQ := 0.0, -1.0
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		q := 1.0
+		for _, p := range params[1:] {
+			if v := strings.TrimSpace(p); strings.HasPrefix(v, "q=") {
+				if parsed, err := strconv.ParseFloat(v[2:], 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		switch mediaType {
+		case "application/json", "application/*", "*/*":
+			if q > defaultQ {
+				defaultQ = q
+			}
+			continue
+		}
+		for i := range blogRepresentations {
+			rep := &blogRepresentations[i]
+			if rep.ext != "json" && rep.mediaType == mediaType && q > bestQ {
+				best, bestQ = rep, q
+			}
+		}
+	}
+	if best == nil || bestQ <= defaultQ {
+		return nil
+	}
+	return best
+}
+
+// 发送博客的某种表示（不计入浏览次数）
+func sendBlogRepresentation(w http.ResponseWriter, r *http.Request, blog *Blog, rep *blogRepresentation) {
+	loc, err := displayLocation(r, blog.AuthorID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body, err := rep.render(localizeBlog(blog, loc))
+	if err != nil {
+		log.Printf("Failed to render blog %s as %s: %v", blog.ID, rep.ext, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	contentType := rep.mediaType
+	if strings.HasPrefix(contentType, "text/") {
+		contentType += "; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Link", blogAlternateLinks(blog.ID))
+	w.Header().Set("Last-Modified", blog.UpdatedTime.Format(http.TimeFormat))
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+var blogRepresentationPath = regexp.MustCompile(`^/api/blogs/([0-9A-Za-z_-]+)\.(md|txt|html|json)$`)
+
+// 按扩展名获取博客的表示：GET /api/blogs/{id}.md|.txt|.html|.json
+func blogRepresentationHandler(w http.ResponseWriter, r *http.Request) {
+	matches := blogRepresentationPath.FindStringSubmatch(r.URL.Path)
+	id, err := parseBlogID(matches[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	blog, err := LoadBlog(id)
+	if err != nil || !canReadBlog(r, blog) {
+		http.Error(w, "Blog not found", http.StatusNotFound)
+		return
+	}
+	for i := range blogRepresentations {
+		if blogRepresentations[i].ext == matches[2] {
+			sendBlogRepresentation(w, r, blog, &blogRepresentations[i])
+			return
+		}
+	}
+}
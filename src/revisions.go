@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 历史版本目录，每篇博客一个子目录，每个版本一个文件
+const revisionDir = "data/revisions"
+
+// Revision 历史版本摘要
+type Revision struct {
+	Version     int       `json:"version"`
+	Title       string    `json:"title"`
+	UpdatedTime time.Time `json:"updated_at"`
+	IsPublished bool      `json:"is_published"` // 该版本保存时是否已发布
+}
+
+func revisionPath(id BlogID, version int) string {
+	return filepath.Join(revisionDir, string(id), strconv.Itoa(version)+".json")
+}
+
+// 保存博客的当前版本快照
+func saveRevision(old, blog *Blog) {
+	if err := os.MkdirAll(filepath.Join(revisionDir, string(blog.ID)), 0755); err != nil {
+		log.Printf("Failed to create revision directory: %v", err)
+		return
+	}
+	data, err := json.MarshalIndent(blog, "", "  ")
+	if err != nil {
+		log.Printf("Failed to marshal revision: %v", err)
+		return
+	}
+	if err := os.WriteFile(revisionPath(blog.ID, blog.Version), data, 0644); err != nil {
+		log.Printf("Failed to write revision: %v", err)
+	}
+}
+
+func init() {
+	onBlogSaved(saveRevision)
+}
+
+// 加载某个历史版本
+func loadRevision(id BlogID, version int) (*Blog, error) {
+	data, err := os.ReadFile(revisionPath(id, version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revision: %w", err)
+	}
+	var blog Blog
+	if err := json.Unmarshal(data, &blog); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal revision: %w", err)
+	}
+	return &blog, nil
+}
+
+// 列出博客的历史版本，新版本在前
+func listRevisions(id BlogID) ([]Revision, error) {
+	entries, err := os.ReadDir(filepath.Join(revisionDir, string(id)))
+	if os.IsNotExist(err) {
+		return []Revision{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revision directory: %w", err)
+	}
+	revisions := []Revision{}
+	for _, e := range entries {
+		version, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		b, err := loadRevision(id, version)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, Revision{Version: b.Version, Title: b.Title, UpdatedTime: b.UpdatedTime, IsPublished: b.IsPublished})
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Version > revisions[j].Version })
+	return revisions, nil
+}
+
+var blogRevisionsPath = regexp.MustCompile("^/api/blogs/([0-9A-Za-z_-]+)/revisions(?:/([0-9]+))?$")
+
+// 历史版本处理器：GET /api/blogs/{id}/revisions 列出版本，/api/blogs/{id}/revisions/{version} 获取某一版本
+func revisionsHandler(w http.ResponseWriter, r *http.Request) {
+	matches := blogRevisionsPath.FindStringSubmatch(r.URL.Path)
+	id, err := parseBlogID(matches[1])
+	if err != nil {
+		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// 与读取博客相同：草稿需要API密钥；匿名请求只能看到已发布的版本
+	current, err := LoadBlog(id)
+	if err != nil || !canReadBlog(r, current) {
+		sendResponse(w, false, "", nil, "Blog not found", http.StatusNotFound)
+		return
+	}
+	drafts := requestAPIKey(r) != nil
+
+	if matches[2] == "" {
+		all, err := listRevisions(id)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to list revisions", http.StatusInternalServerError)
+			return
+		}
+		revisions := []Revision{}
+		for _, rev := range all {
+			if rev.IsPublished || drafts {
+				revisions = append(revisions, rev)
+			}
+		}
+		sendResponse(w, true, "Revisions retrieved successfully", revisionLinks(id, revisions), "", http.StatusOK)
+		return
+	}
+
+	version, _ := strconv.Atoi(matches[2])
+	blog, err := loadRevision(id, version)
+	if err != nil || !blog.IsPublished && !drafts {
+		sendResponse(w, false, "", nil, "Revision not found", http.StatusNotFound)
+		return
+	}
+	links := halLinks{
+		"self":           halLink{Href: fmt.Sprintf("%s/revisions/%d", blogURL(id), version)},
+		"latest-version": halLink{Href: blogURL(id)},
+		"revisions":      halLink{Href: blogURL(id) + "/revisions"},
+	}
+	sendResponse(w, true, "Revision retrieved successfully", halBlog{Blog: blog, Links: links}, "", http.StatusOK)
+}
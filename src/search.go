@@ -0,0 +1,437 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 搜索日志目录，每天一个JSONL文件。日志不含IP、密钥、UA等可识别访客的信息，时间精确到小时。
+const searchLogDir = "data/search"
+
+// 同义词文件：每组内的词互为同义词
+const synonymFile = "data/synonyms.json"
+
+// 查询最多记录的字符数
+const maxLoggedQuery = 100
+
+func init() {
+	if err := os.MkdirAll(searchLogDir, 0755); err != nil {
+		log.Fatalf("Failed to create search log directory: %v", err)
+	}
+}
+
+// searchEvent 搜索日志事件：search 为一次搜索，click 为点击了某个结果
+type searchEvent struct {
+	Type     string    `json:"type"`
+	SearchID string    `json:"search_id"`
+	Time     time.Time `json:"time"`
+	Query    string    `json:"query,omitempty"`
+	Results  int       `json:"results"`
+	BlogID   BlogID    `json:"blog_id,omitempty"`
+	Position int       `json:"position,omitempty"`
+}
+
+// searchResult 搜索结果
+type searchResult struct {
+	ID      BlogID   `json:"id"`
+	Title   string   `json:"title"`
+	Excerpt string   `json:"excerpt"`
+	Tags    []string `json:"tags,omitempty"`
+	Score   int      `json:"score"`
+}
+
+// searchResponse 搜索响应，点击结果时需回传 search_id
+type searchResponse struct {
+	SearchID string         `json:"search_id"`
+	Query    string         `json:"query"`
+	Total    int            `json:"total"`
+	Results  []searchResult `json:"results"`
+}
+
+var searchLogMu sync.Mutex
+
+// 规范化查询：去除首尾空白、转小写、合并连续空白
+func normalizeQuery(q string) string {
+	return strings.Join(strings.Fields(strings.ToLower(q)), " ")
+}
+
+// 加载同义词组，文件不存在时返回空列表
+func loadSynonyms() ([][]string, error) {
+	data, err := os.ReadFile(synonymFile)
+	if os.IsNotExist(err) {
+		return [][]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read synonym file: %w", err)
+	}
+	var groups [][]string
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal synonyms: %w", err)
+	}
+	return groups, nil
+}
+
+func saveSynonyms(groups [][]string) error {
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal synonyms: %w", err)
+	}
+	if err := os.WriteFile(synonymFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write synonym file: %w", err)
+	}
+	return nil
+}
+
+// 将查询词扩展为其同义词（包括自身）
+func expandTerm(term string, groups [][]string) []string {
+	terms := []string{term}
+	for _, group := range groups {
+		for _, word := range group {
+			if normalizeQuery(word) != term {
+				continue
+			}
+			for _, syn := range group {
+				if syn = normalizeQuery(syn); syn != term {
+					terms = append(terms, syn)
+				}
+			}
+			break
+		}
+	}
+	return terms
+}
+
+// 在已发布的博客中搜索：每个查询词（或其同义词）都必须出现在标题、标签或正文中
+func searchBlogs(query string, groups [][]string) ([]searchResult, error) {
+	blogs, err := publishedBlogs("")
+	if err != nil {
+		return nil, err
+	}
+	terms := strings.Fields(query)
+	results := []searchResult{}
+	for _, b := range blogs {
+		title := strings.ToLower(b.Title)
+		tags := strings.ToLower(strings.Join(b.Tags, " "))
+		text := renderBlogText(b)
+		lower := strings.ToLower(text)
+
+		score := 0
+		for _, term := range terms {
+			best := 0
+			for _, t := range expandTerm(term, groups) {
+				s := 0
+				if strings.Contains(title, t) {
+					s += 5
+				}
+				if strings.Contains(tags, t) {
+					s += 3
+				}
+				if strings.Contains(lower, t) {
+					s++
+				}
+				if s > best {
+					best = s
+				}
+			}
+			if best == 0 {
+				score = 0
+				break
+			}
+			score += best
+		}
+		if score > 0 {
+			results = append(results, searchResult{ID: b.ID, Title: b.Title, Excerpt: excerpt(text, 120), Tags: b.Tags, Score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results, nil
+}
+
+// 追加搜索日志
+func logSearchEvent(e searchEvent) {
+	e.Time = e.Time.UTC().Truncate(time.Hour)
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("Failed to marshal search event: %v", err)
+		return
+	}
+
+	searchLogMu.Lock()
+	defer searchLogMu.Unlock()
+	f, err := os.OpenFile(filepath.Join(searchLogDir, e.Time.Format("2006-01-02")+".jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Failed to open search log: %v", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		log.Printf("Failed to write search log: %v", err)
+	}
+}
+
+// 搜索处理器：GET /api/search?q=&limit=
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := normalizeQuery(r.URL.Query().Get("q"))
+	if query == "" {
+		sendResponse(w, false, "", nil, "q is required", http.StatusBadRequest)
+		return
+	}
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			sendResponse(w, false, "", nil, "limit must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	groups, err := loadSynonyms()
+	if err != nil {
+		log.Printf("Failed to load synonyms: %v", err)
+	}
+	results, err := searchBlogs(query, groups)
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to search blogs", http.StatusInternalServerError)
+		return
+	}
+
+	resp := searchResponse{SearchID: newULID(time.Now()), Query: query, Total: len(results), Results: results}
+	if len(results) > limit {
+		resp.Results = results[:limit]
+	}
+
+	logged := query
+	if runes := []rune(logged); len(runes) > maxLoggedQuery {
+		logged = string(runes[:maxLoggedQuery])
+	}
+	logSearchEvent(searchEvent{Type: "search", SearchID: resp.SearchID, Time: time.Now(), Query: logged, Results: len(results)})
+
+	sendResponse(w, true, "Search completed", resp, "", http.StatusOK)
+}
+
+// 结果点击处理器：POST /api/search/click {"search_id":"...","blog_id":1,"position":1}
+func searchClickHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 4096))
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	var click searchEvent
+	if err := json.Unmarshal(body, &click); err != nil {
+		sendResponse(w, false, "", nil, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if click.SearchID == "" || click.BlogID == "" || click.Position < 1 {
+		sendResponse(w, false, "", nil, "search_id, blog_id and position are required", http.StatusBadRequest)
+		return
+	}
+	logSearchEvent(searchEvent{Type: "click", SearchID: click.SearchID, Time: time.Now(), BlogID: click.BlogID, Position: click.Position})
+	sendResponse(w, true, "Click recorded", nil, "", http.StatusOK)
+}
+
+// queryStats 某个查询在统计区间内的汇总
+type queryStats struct {
+	Query       string  `json:"query"`
+	Searches    int     `json:"searches"`
+	ZeroResults int     `json:"zero_results"`
+	Clicks      int     `json:"clicks"`
+	CTR         float64 `json:"ctr"`                   // 有点击的搜索占比
+	NowMatches  *int    `json:"now_matches,omitempty"` // 按当前内容和同义词重新搜索的结果数
+}
+
+// searchReport 搜索分析报告
+type searchReport struct {
+	Days        int           `json:"days"`
+	Searches    int           `json:"searches"`
+	TopQueries  []*queryStats `json:"top_queries"`
+	ZeroResults []*queryStats `json:"zero_result_queries"`
+	LowCTR      []*queryStats `json:"low_ctr_queries"`
+}
+
+// 汇总最近 days 天的搜索日志
+func buildSearchReport(days, limit, minSearches int) (*searchReport, error) {
+	stats := make(map[string]*queryStats)
+	searchQuery := make(map[string]string)
+	clicked := make(map[string]bool)
+	report := &searchReport{Days: days}
+
+	today := time.Now().UTC()
+	for d := days - 1; d >= 0; d-- {
+		f, err := os.Open(filepath.Join(searchLogDir, today.AddDate(0, 0, -d).Format("2006-01-02")+".jsonl"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open search log: %w", err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var e searchEvent
+			if json.Unmarshal(scanner.Bytes(), &e) != nil {
+				continue
+			}
+			switch e.Type {
+			case "search":
+				s, ok := stats[e.Query]
+				if !ok {
+					s = &queryStats{Query: e.Query}
+					stats[e.Query] = s
+				}
+				s.Searches++
+				if e.Results == 0 {
+					s.ZeroResults++
+				}
+				searchQuery[e.SearchID] = e.Query
+				report.Searches++
+			case "click":
+				// 同一次搜索的多次点击只计一次
+				if q, ok := searchQuery[e.SearchID]; ok && !clicked[e.SearchID] {
+					clicked[e.SearchID] = true
+					stats[q].Clicks++
+				}
+			}
+		}
+		f.Close()
+	}
+
+	all := make([]*queryStats, 0, len(stats))
+	for _, s := range stats {
+		s.CTR = float64(s.Clicks) / float64(s.Searches)
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Searches != all[j].Searches {
+			return all[i].Searches > all[j].Searches
+		}
+		return all[i].Query < all[j].Query
+	})
+
+	groups, err := loadSynonyms()
+	if err != nil {
+		return nil, err
+	}
+	report.TopQueries, report.ZeroResults, report.LowCTR = []*queryStats{}, []*queryStats{}, []*queryStats{}
+	for _, s := range all {
+		if len(report.TopQueries) < limit {
+			report.TopQueries = append(report.TopQueries, s)
+		}
+		if s.ZeroResults > 0 && len(report.ZeroResults) < limit {
+			// 重新搜索，便于确认同义词或新内容是否已解决
+			results, err := searchBlogs(s.Query, groups)
+			if err != nil {
+				return nil, err
+			}
+			n := len(results)
+			s.NowMatches = &n
+			report.ZeroResults = append(report.ZeroResults, s)
+		}
+		if s.Searches >= minSearches && s.ZeroResults < s.Searches && s.CTR < 0.2 && len(report.LowCTR) < limit {
+			report.LowCTR = append(report.LowCTR, s)
+		}
+	}
+	return report, nil
+}
+
+// 搜索报告：GET /api/admin/search/report?days=30&limit=20&min_searches=3（需要管理员密钥）
+func searchReportHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	params := map[string]int{"days": 30, "limit": 20, "min_searches": 3}
+	for name := range params {
+		if v := r.URL.Query().Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 1000 {
+				sendResponse(w, false, "", nil, "Invalid "+name, http.StatusBadRequest)
+				return
+			}
+			params[name] = n
+		}
+	}
+	report, err := buildSearchReport(params["days"], params["limit"], params["min_searches"])
+	if err != nil {
+		log.Printf("Failed to build search report: %v", err)
+		sendResponse(w, false, "", nil, "Failed to build search report", http.StatusInternalServerError)
+		return
+	}
+	sendResponse(w, true, "Search report generated", report, "", http.StatusOK)
+}
+
+// 同义词编辑：GET/PUT /api/admin/search/synonyms，请求体为词组数组，如 [["golang","go"],["k8s","kubernetes"]]
+func synonymsHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		groups, err := loadSynonyms()
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to load synonyms", http.StatusInternalServerError)
+			return
+		}
+		sendResponse(w, true, "Synonyms retrieved successfully", groups, "", http.StatusOK)
+
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		var groups [][]string
+		if err := json.Unmarshal(body, &groups); err != nil {
+			sendResponse(w, false, "", nil, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		seen := make(map[string]bool)
+		cleaned := make([][]string, 0, len(groups))
+		for _, group := range groups {
+			var words []string
+			for _, word := range group {
+				word = normalizeQuery(word)
+				if word == "" || strings.Contains(word, " ") {
+					sendResponse(w, false, "", nil, fmt.Sprintf("invalid synonym %q: must be a single word", word), http.StatusBadRequest)
+					return
+				}
+				if seen[word] {
+					sendResponse(w, false, "", nil, fmt.Sprintf("synonym %q appears in more than one group", word), http.StatusBadRequest)
+					return
+				}
+				seen[word] = true
+				words = append(words, word)
+			}
+			if len(words) < 2 {
+				sendResponse(w, false, "", nil, "each synonym group needs at least two words", http.StatusBadRequest)
+				return
+			}
+			cleaned = append(cleaned, words)
+		}
+		if err := saveSynonyms(cleaned); err != nil {
+			sendResponse(w, false, "", nil, "Failed to save synonyms", http.StatusInternalServerError)
+			return
+		}
+		sendResponse(w, true, "Synonyms saved successfully", cleaned, "", http.StatusOK)
+
+	default:
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
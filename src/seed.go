@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// 开发用作者
+var seedAuthors = []Author{
+	{ID: 1, Name: "张伟", Timezone: "Asia/Shanghai"},
+	{ID: 2, Name: "Emily Carter", Timezone: "America/New_York"},
+	{ID: 3, Name: "李娜", Timezone: "Asia/Taipei"},
+	{ID: 4, Name: "Oliver Smith", Timezone: "Europe/London"},
+	{ID: 5, Name: "王芳", Timezone: "UTC"},
+}
+
+var seedTags = []string{"golang", "编程", "教程", "web", "数据库", "devops", "生活", "读书", "performance", "测试", "security", "随笔"}
+
+// 中文素材
+var (
+	seedZhTopics = []string{"Go 并发模型", "数据库索引", "缓存策略", "日志系统", "单元测试", "容器部署", "接口设计", "性能优化", "错误处理", "代码评审", "周末读书", "城市骑行"}
+	seedZhTitles = []string{"浅谈%s", "%s实践笔记", "我对%s的一些思考", "从零开始理解%s", "%s踩坑记录", "关于%s的五个建议"}
+	seedZhParas  = []string{
+		"最近在项目中遇到了一个有意思的问题，花了不少时间才定位到根本原因。",
+		"这里记录一下整个排查过程，希望对遇到类似情况的朋友有所帮助。",
+		"一开始我以为是配置的问题，后来发现其实和并发访问有关。",
+		"官方文档对这一点的描述比较简略，需要结合源码才能看明白。",
+		"在生产环境中，我们更关心的是稳定性和可观测性，而不是极致的性能。",
+		"如果只是个人项目，完全可以先用最简单的方案，等遇到瓶颈再优化。",
+		"经过几轮压测，最终的结果比预期要好，延迟下降了大约三成。",
+		"总的来说，这次改动让代码更容易维护，也为后续的功能打下了基础。",
+	}
+	seedZhItems = []string{"先写测试再改代码", "保持函数短小", "错误要带上下文", "避免全局状态", "日志里不要打印密钥", "给超时设置合理的默认值"}
+)
+
+// 英文素材
+var (
+	seedEnTopics = []string{"Go Generics", "Database Migrations", "HTTP Caching", "Structured Logging", "Table-Driven Tests", "Zero-Downtime Deploys", "API Pagination", "Profiling", "Error Wrapping", "Code Review", "Remote Work", "Weekend Hiking"}
+	seedEnTitles = []string{"Notes on %s", "A Practical Guide to %s", "What I Learned About %s", "%s in Production", "Rethinking %s", "Five Tips for %s"}
+	seedEnParas  = []string{
+		"I ran into an interesting problem last week that took longer than expected to track down.",
+		"Here is a write-up of the investigation in case it saves someone else an afternoon.",
+		"At first it looked like a configuration issue, but it turned out to be a race condition.",
+		"The documentation only hints at this behaviour, so I ended up reading the source.",
+		"In production we care more about predictability and observability than raw speed.",
+		"For a side project the simplest approach is usually fine until it clearly is not.",
+		"After a few rounds of load testing, p99 latency dropped by roughly a third.",
+		"Overall the change made the code easier to reason about and set us up for the next feature.",
+	}
+	seedEnItems = []string{"write the test first", "keep functions small", "wrap errors with context", "avoid global state", "never log secrets", "pick sensible timeout defaults"}
+)
+
+var seedCode = []string{
+	"```go\nfunc main() {\n\tfmt.Println(\"hello, world\")\n}\n```",
+	"```sql\nCREATE INDEX idx_blogs_created ON blogs (created_at DESC);\n```",
+	"```bash\ngo test ./... -race -count=1\n```",
+}
+
+// 生成开发用博客数据：blog seed [-n 50] [-seed 1] [-days 730]
+func runSeed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	n := fs.Int("n", 50, "number of posts to generate")
+	seed := fs.Int64("seed", 1, "random seed; the same seed produces the same posts")
+	days := fs.Int("days", 730, "spread creation dates over this many days before -end")
+	end := fs.String("end", time.Now().UTC().Format("2006-01-02"), "latest creation date (YYYY-MM-DD); fix it for fully reproducible dates")
+	fs.Parse(args)
+
+	if *n < 1 || *days < 1 {
+		return fmt.Errorf("-n and -days must be positive")
+	}
+	endDate, err := time.Parse("2006-01-02", *end)
+	if err != nil {
+		return fmt.Errorf("invalid -end: %w", err)
+	}
+
+	for i := range seedAuthors {
+		if _, err := LoadAuthor(seedAuthors[i].ID); err == nil {
+			continue
+		}
+		if err := seedAuthors[i].Save(); err != nil {
+			return err
+		}
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	published := 0
+	for i := 0; i < *n; i++ {
+		blog := seedBlog(rng, endDate, *days)
+		blog.ID = newBlogID()
+		if err := SaveBlog(blog); err != nil {
+			return fmt.Errorf("failed to save seeded blog: %w", err)
+		}
+		if blog.IsPublished {
+			published++
+		}
+	}
+	fmt.Printf("Seeded %d blog(s) (%d published) with seed %d\n", *n, published, *seed)
+	return nil
+}
+
+// 生成一篇随机博客（约一半中文、一半英文）
+func seedBlog(rng *rand.Rand, end time.Time, days int) *Blog {
+	zh := rng.Intn(2) == 0
+	topics, titles, paras, items := seedEnTopics, seedEnTitles, seedEnParas, seedEnItems
+	if zh {
+		topics, titles, paras, items = seedZhTopics, seedZhTitles, seedZhParas, seedZhItems
+	}
+
+	pick := func(list []string) string { return list[rng.Intn(len(list))] }
+	paragraph := func() string {
+		var parts []string
+		for _, i := range rng.Perm(len(paras))[:2+rng.Intn(3)] {
+			parts = append(parts, paras[i])
+		}
+		sep := " "
+		if zh {
+			sep = ""
+		}
+		return strings.Join(parts, sep)
+	}
+
+	var sections []string
+	sections = append(sections, paragraph())
+	for s, count := 0, 1+rng.Intn(4); s < count; s++ {
+		heading := fmt.Sprintf("Part %d", s+1)
+		if zh {
+			heading = fmt.Sprintf("第%d部分", s+1)
+		}
+		sections = append(sections, "## "+heading, paragraph())
+
+		switch rng.Intn(6) {
+		case 0:
+			sections = append(sections, pick(seedCode))
+		case 1:
+			var list []string
+			for i, count := 0, 2+rng.Intn(3); i < count; i++ {
+				prefix := "- "
+				if s%2 == 1 {
+					prefix = fmt.Sprintf("%d. ", i+1)
+				}
+				list = append(list, prefix+pick(items))
+			}
+			sections = append(sections, strings.Join(list, "\n"))
+		case 2:
+			sections = append(sections, "> "+pick(paras))
+		case 3:
+			style := []string{"NOTE", "TIP", "WARNING"}[rng.Intn(3)]
+			sections = append(sections, "> [!"+style+"]\n> "+pick(paras))
+		case 4:
+			link := fmt.Sprintf("See [the docs](https://example.com/docs/%d) for **details**.", rng.Intn(100))
+			if zh {
+				link = fmt.Sprintf("详细内容请参考[官方文档](https://example.com/docs/%d)中的**说明**。", rng.Intn(100))
+			}
+			sections = append(sections, link)
+		}
+	}
+
+	tags := make([]string, 0, 3)
+	for _, i := range rng.Perm(len(seedTags))[:rng.Intn(4)] {
+		tags = append(tags, seedTags[i])
+	}
+
+	created := end.Add(-time.Duration(rng.Int63n(int64(days)*24*3600)) * time.Second)
+	return &Blog{
+		Title:       fmt.Sprintf(pick(titles), pick(topics)),
+		AuthorID:    seedAuthors[rng.Intn(len(seedAuthors))].ID,
+		Content:     strings.Join(sections, "\n\n"),
+		Tags:        tags,
+		CreatedTime: created,
+		IsPublished: rng.Intn(5) != 0, // 约八成已发布
+		ViewCount:   rng.Intn(5000),
+	}
+}
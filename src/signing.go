@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Signature 已发布博客版本的签名
+type Signature struct {
+	Algorithm string    `json:"algorithm"` // 固定为 ed25519
+	KeyID     string    `json:"key_id"`    // 公钥摘要前缀
+	Value     string    `json:"value"`     // 对规范化JSON的签名（Base64）
+	SignedAt  time.Time `json:"signed_at"` // 签名时间
+}
+
+// 签名密钥目录，私钥以Base64保存32字节种子
+const keyDir = "data/keys"
+
+var signingKeyFile = filepath.Join(keyDir, "signing.key")
+
+// 订阅中签名扩展的命名空间
+const signatureNS = "urn:x-blog:signature:1"
+
+var (
+	signingKeyMu sync.Mutex
+	signingKey   ed25519.PrivateKey
+)
+
+// 加载签名私钥，不存在时生成新密钥
+func loadSigningKey() (ed25519.PrivateKey, error) {
+	signingKeyMu.Lock()
+	defer signingKeyMu.Unlock()
+	if signingKey != nil {
+		return signingKey, nil
+	}
+
+	data, err := os.ReadFile(signingKeyFile)
+	if os.IsNotExist(err) {
+		seed := make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		if err := os.MkdirAll(keyDir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create key directory: %w", err)
+		}
+		if err := os.WriteFile(signingKeyFile, []byte(base64.StdEncoding.EncodeToString(seed)+"\n"), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write signing key: %w", err)
+		}
+		log.Printf("Generated new signing key %s", signingKeyFile)
+		signingKey = ed25519.NewKeyFromSeed(seed)
+		return signingKey, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid signing key in %s", signingKeyFile)
+	}
+	signingKey = ed25519.NewKeyFromSeed(seed)
+	return signingKey, nil
+}
+
+// 公钥ID：公钥SHA-256的前16个十六进制字符
+func signingKeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// canonicalBlog 参与签名的字段，按键名字母序排列；
+// 内容块优先于 Content 渲染，也需要签名（没有内容块时省略，与之前的签名兼容）
+type canonicalBlog struct {
+	AuthorID  int      `json:"author_id"`
+	Blocks    []Block  `json:"blocks,omitempty"`
+	Content   string   `json:"content"`
+	CreatedAt string   `json:"created_at"`
+	ID        string   `json:"id"`
+	LinkURL   string   `json:"link_url"`
+	Tags      []string `json:"tags"`
+	Title     string   `json:"title"`
+	Version   int      `json:"version"`
+}
+
+// 博客的规范化JSON：固定字段顺序、无空白、不转义HTML字符、时间统一为UTC RFC3339Nano。
+// 浏览次数、更新时间等会在内容不变时变化的字段不参与签名。
+func canonicalBlogJSON(b *Blog) ([]byte, error) {
+	tags := b.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(canonicalBlog{
+		AuthorID:  b.AuthorID,
+		Blocks:    b.Blocks,
+		Content:   b.Content,
+		CreatedAt: b.CreatedTime.UTC().Format(time.RFC3339Nano),
+		ID:        string(b.ID),
+		LinkURL:   b.LinkURL,
+		Tags:      tags,
+		Title:     b.Title,
+		Version:   b.Version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal canonical blog: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// 为已发布的博客版本签名，未发布的博客清除签名
+func signBlog(b *Blog) error {
+	if !b.IsPublished {
+		b.Signature = nil
+		return nil
+	}
+	key, err := loadSigningKey()
+	if err != nil {
+		return err
+	}
+	if b.CreatedTime.IsZero() {
+		b.CreatedTime = time.Now().UTC()
+	}
+	payload, err := canonicalBlogJSON(b)
+	if err != nil {
+		return err
+	}
+	b.Signature = &Signature{
+		Algorithm: "ed25519",
+		KeyID:     signingKeyID(key.Public().(ed25519.PublicKey)),
+		Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+		SignedAt:  time.Now().UTC(),
+	}
+	return nil
+}
+
+// 用公钥验证博客签名
+func verifyBlog(b *Blog, pub ed25519.PublicKey) error {
+	if b.Signature == nil {
+		return fmt.Errorf("blog is not signed")
+	}
+	if b.Signature.Algorithm != "ed25519" {
+		return fmt.Errorf("unsupported algorithm %q", b.Signature.Algorithm)
+	}
+	if b.Signature.KeyID != signingKeyID(pub) {
+		return fmt.Errorf("signed with unknown key %s", b.Signature.KeyID)
+	}
+	sig, err := base64.StdEncoding.DecodeString(b.Signature.Value)
+	if err != nil {
+		return fmt.Errorf("malformed signature")
+	}
+	payload, err := canonicalBlogJSON(b)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, payload, sig) {
+		return fmt.Errorf("signature does not match content")
+	}
+	return nil
+}
+
+// 站点公钥
+func signingPublicKey() (ed25519.PublicKey, error) {
+	key, err := loadSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	return key.Public().(ed25519.PublicKey), nil
+}
+
+// signingKeyInfo 对外公开的公钥信息
+type signingKeyInfo struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"` // Base64
+}
+
+func publicSigningKeyInfo() (*signingKeyInfo, error) {
+	pub, err := signingPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return &signingKeyInfo{Algorithm: "ed25519", KeyID: signingKeyID(pub), PublicKey: base64.StdEncoding.EncodeToString(pub)}, nil
+}
+
+// 公钥处理器：GET /api/signing-key
+func signingKeyHandler(w http.ResponseWriter, r *http.Request) {
+	info, err := publicSigningKeyInfo()
+	if err != nil {
+		log.Printf("Failed to load signing key: %v", err)
+		sendResponse(w, false, "", nil, "Failed to load signing key", http.StatusInternalServerError)
+		return
+	}
+	sendResponse(w, true, "Signing key retrieved successfully", info, "", http.StatusOK)
+}
+
+// verifyResult 验证结果
+type verifyResult struct {
+	Valid bool   `json:"valid"`
+	KeyID string `json:"key_id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// 验证处理器：POST /api/verify，请求体为带签名的博客JSON（如从镜像站获取的副本）
+func verifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	var blog Blog
+	if err := json.Unmarshal(body, &blog); err != nil {
+		sendResponse(w, false, "", nil, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	pub, err := signingPublicKey()
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to load signing key", http.StatusInternalServerError)
+		return
+	}
+
+	result := verifyResult{Valid: true, KeyID: signingKeyID(pub)}
+	if err := verifyBlog(&blog, pub); err != nil {
+		result.Valid, result.Error = false, err.Error()
+	}
+	sendResponse(w, true, "Verification completed", result, "", http.StatusOK)
+}
+
+// 为缺少有效签名的已发布博客补签：blog sign
+func runSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	fs.Parse(args)
+
+	pub, err := signingPublicKey()
+	if err != nil {
+		return err
+	}
+	blogs, err := ListBlogs()
+	if err != nil {
+		return err
+	}
+	signed := 0
+	for _, b := range blogs {
+		if !b.IsPublished || verifyBlog(b, pub) == nil {
+			continue
+		}
+		if err := signBlog(b); err != nil {
+			return err
+		}
+		if err := b.write(); err != nil {
+			return err
+		}
+		signed++
+	}
+	fmt.Printf("Signed %d blog(s) with key %s\n", signed, signingKeyID(pub))
+	return nil
+}
+
+// 验证签名：blog verify [-key base64] [-file blog.json] [id...]，不指定时验证全部已发布博客
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	keyFlag := fs.String("key", "", "Base64 public key to verify against (defaults to this site's key)")
+	file := fs.String("file", "", "verify a blog JSON file instead of stored blogs")
+	fs.Parse(args)
+
+	var pub ed25519.PublicKey
+	if *keyFlag != "" {
+		raw, err := base64.StdEncoding.DecodeString(*keyFlag)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid public key")
+		}
+		pub = raw
+	} else {
+		var err error
+		if pub, err = signingPublicKey(); err != nil {
+			return err
+		}
+	}
+
+	var blogs []*Blog
+	switch {
+	case *file != "":
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		// 兼容API响应格式（博客位于 data 字段中）
+		var envelope struct {
+			Data *Blog `json:"data"`
+		}
+		var blog Blog
+		if json.Unmarshal(data, &envelope) == nil && envelope.Data != nil {
+			blog = *envelope.Data
+		} else if err := json.Unmarshal(data, &blog); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", *file, err)
+		}
+		blogs = append(blogs, &blog)
+	case fs.NArg() > 0:
+		for _, arg := range fs.Args() {
+			id, err := parseBlogID(arg)
+			if err != nil {
+				return err
+			}
+			b, err := LoadBlog(id)
+			if err != nil {
+				return err
+			}
+			blogs = append(blogs, b)
+		}
+	default:
+		all, err := publishedBlogs("")
+		if err != nil {
+			return err
+		}
+		blogs = all
+	}
+
+	failed := 0
+	for _, b := range blogs {
+		if err := verifyBlog(b, pub); err != nil {
+			failed++
+			fmt.Printf("FAIL %s v%d: %v\n", b.ID, b.Version, err)
+			continue
+		}
+		fmt.Printf("OK   %s v%d\n", b.ID, b.Version)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d signature(s) failed verification", failed, len(blogs))
+	}
+	return nil
+}
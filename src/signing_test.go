@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestSignatureCoversBlocks(t *testing.T) {
+	pub, err := signingPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Blog{ID: "1", Title: "Signed", Content: "fallback", IsPublished: true, Version: 1,
+		Blocks: []Block{{Type: "paragraph", Text: "Original text"}}}
+	if err := signBlog(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyBlog(b, pub); err != nil {
+		t.Fatalf("freshly signed blog: %v", err)
+	}
+
+	b.Blocks[0].Text = "Replaced text"
+	if err := verifyBlog(b, pub); err == nil {
+		t.Error("changed block passed verification")
+	}
+	b.Blocks[0].Text = "Original text"
+	b.Blocks = append(b.Blocks, Block{Type: "paragraph", Text: "Added"})
+	if err := verifyBlog(b, pub); err == nil {
+		t.Error("added block passed verification")
+	}
+
+	// 没有内容块的博客：签名与加入内容块之前的规范化格式相同
+	plain := &Blog{ID: "2", Title: "Plain", Content: "text", IsPublished: true, Version: 1}
+	payload, err := canonicalBlogJSON(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"author_id":0,"content":"text","created_at":"0001-01-01T00:00:00Z","id":"2","link_url":"","tags":[],"title":"Plain","version":1}`; string(payload) != want {
+		t.Errorf("canonical JSON = %s", payload)
+	}
+}
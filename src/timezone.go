@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// 内置时区数据库，避免依赖服务器上的 zoneinfo
+	_ "time/tzdata"
+)
+
+// 站点时区，用于归档等按日期划分的功能
+func siteLocation() *time.Location {
+	if config.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		log.Printf("Invalid site timezone %q, falling back to UTC: %v", config.Timezone, err)
+		return time.UTC
+	}
+	return loc
+}
+
+// 确定显示时区：请求参数 ?tz= 优先，其次是作者设置的时区，最后是站点时区
+func displayLocation(r *http.Request, authorID int) (*time.Location, error) {
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone: %s", tz)
+		}
+		return loc, nil
+	}
+	if authorID != 0 {
+		if author, err := LoadAuthor(authorID); err == nil && author.Timezone != "" {
+			if loc, err := time.LoadLocation(author.Timezone); err == nil {
+				return loc, nil
+			}
+		}
+	}
+	return siteLocation(), nil
+}
+
+// 返回时间转换到指定时区后的博客副本
+func localizeBlog(b *Blog, loc *time.Location) *Blog {
+	localized := *b
+	localized.CreatedTime = b.CreatedTime.In(loc)
+	localized.UpdatedTime = b.UpdatedTime.In(loc)
+	return &localized
+}
+
+// 将已有博客文件中的时间统一转换为UTC：blog migrate-timestamps [-dry-run]
+func runMigrateTimestamps(args []string) error {
+	fs := flag.NewFlagSet("migrate-timestamps", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only report files that would be rewritten")
+	fs.Parse(args)
+
+	files, err := os.ReadDir(blogDir)
+	if err != nil {
+		return fmt.Errorf("failed to read blog directory: %w", err)
+	}
+
+	migrated := 0
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(blogDir, f.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Name(), err)
+		}
+		var blog Blog
+		if err := json.Unmarshal(raw, &blog); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", f.Name(), err)
+		}
+		// 已是UTC的文件无需重写
+		if blog.CreatedTime.Location() == time.UTC && blog.UpdatedTime.Location() == time.UTC {
+			continue
+		}
+
+		blog.CreatedTime = blog.CreatedTime.UTC()
+		blog.UpdatedTime = blog.UpdatedTime.UTC()
+		if !*dryRun {
+			if err := blog.write(); err != nil {
+				return err
+			}
+		}
+		migrated++
+		fmt.Printf("%s: created_at=%s updated_at=%s\n", f.Name(), blog.CreatedTime.Format(time.RFC3339), blog.UpdatedTime.Format(time.RFC3339))
+	}
+
+	if *dryRun {
+		fmt.Printf("%d blog file(s) would be converted to UTC\n", migrated)
+	} else {
+		fmt.Printf("%d blog file(s) converted to UTC\n", migrated)
+	}
+	return nil
+}
@@ -0,0 +1,632 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blogStore 终端界面使用的博客存取接口
+type blogStore interface {
+	Name() string
+	List() ([]*Blog, error)
+	Save(b *Blog) (*Blog, error)
+}
+
+// localStore 直接读写本地数据目录
+type localStore struct{}
+
+func (localStore) Name() string { return blogDir }
+
+func (localStore) List() ([]*Blog, error) { return ListBlogs() }
+
+func (localStore) Save(b *Blog) (*Blog, error) {
+	if b.ID == "" {
+		b.ID = newBlogID()
+	}
+	if err := SaveBlog(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// remoteStore 通过HTTP API读写远程服务器
+type remoteStore struct {
+	baseURL string
+	apiKey  string // 列出草稿需要API密钥
+	client  *http.Client
+}
+
+func newRemoteStore(baseURL, apiKey string) *remoteStore {
+	return &remoteStore{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (s *remoteStore) Name() string { return s.baseURL }
+
+func (s *remoteStore) List() ([]*Blog, error) {
+	var blogs []*Blog
+	for page := 1; ; page++ {
+		var list BlogList
+		if err := s.do(http.MethodGet, fmt.Sprintf("/api/blogs?published=%s&page=%d&per_page=100", s.listFilter(), page), nil, &list); err != nil {
+			return nil, err
+		}
+		blogs = append(blogs, list.Blogs...)
+		if len(list.Blogs) == 0 || len(blogs) >= list.Total {
+			return blogs, nil
+		}
+	}
+}
+
+// 有密钥时列出全部博客，否则只能列出已发布的博客
+func (s *remoteStore) listFilter() string {
+	if s.apiKey == "" {
+		return "true"
+	}
+	return "all"
+}
+
+func (s *remoteStore) Save(b *Blog) (*Blog, error) {
+	method, path := http.MethodPost, "/api/blogs/"
+	if b.ID != "" {
+		method, path = http.MethodPut, "/api/blogs/"+string(b.ID)
+	}
+	var saved Blog
+	if err := s.do(method, path, b, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// 发送API请求并解析响应中的 data 字段
+func (s *remoteStore) do(method, path string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, s.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid response from server: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("server error: %s", result.Error)
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// 发布状态过滤
+var tuiStatusFilters = []string{"all", "published", "drafts"}
+
+// tui 终端界面状态
+type tui struct {
+	store   blogStore
+	all     []*Blog
+	visible []*Blog
+	cursor  int
+	offset  int // 列表滚动位置
+	scroll  int // 预览滚动位置
+	query   string
+	status  int
+	message string
+	width   int
+	height  int
+	in      *bufio.Reader
+	out     *bufio.Writer
+	restore func()
+}
+
+// 运行终端界面：blog tui [-remote URL [-key KEY]]
+func runTUI(args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	remote := fs.String("remote", "", "base URL of a remote blog server (default: local data directory)")
+	key := fs.String("key", os.Getenv("BLOG_API_KEY"), "API key for the remote server, required to list drafts (or BLOG_API_KEY)")
+	fs.Parse(args)
+
+	var store blogStore = localStore{}
+	if *remote != "" {
+		store = newRemoteStore(*remote, *key)
+	}
+
+	t := &tui{store: store, in: bufio.NewReader(os.Stdin), out: bufio.NewWriter(os.Stdout)}
+	if err := t.reload(); err != nil {
+		return err
+	}
+	if err := t.enterRawMode(); err != nil {
+		return err
+	}
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		t.out.WriteString("\x1b[?25h\x1b[?1049l")
+		t.out.Flush()
+		t.restore()
+	}()
+
+	return t.loop()
+}
+
+// 调用 stty 修改终端模式
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// 切换到逐键读取模式
+func (t *tui) enterRawMode() error {
+	state, err := stty("-g")
+	if err != nil {
+		return fmt.Errorf("tui requires an interactive terminal: %w", err)
+	}
+	if _, err := stty("-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return fmt.Errorf("failed to configure terminal: %w", err)
+	}
+	t.restore = func() { stty(state) }
+	return nil
+}
+
+// 读取终端尺寸
+func (t *tui) updateSize() {
+	t.width, t.height = 100, 30
+	if size, err := stty("size"); err == nil {
+		if fields := strings.Fields(size); len(fields) == 2 {
+			if rows, err := strconv.Atoi(fields[0]); err == nil && rows > 5 {
+				t.height = rows
+			}
+			if cols, err := strconv.Atoi(fields[1]); err == nil && cols > 40 {
+				t.width = cols
+			}
+		}
+	}
+}
+
+// 重新加载博客列表
+func (t *tui) reload() error {
+	blogs, err := t.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to load blogs: %w", err)
+	}
+	t.all = blogs
+	t.applyFilter()
+	return nil
+}
+
+// 按发布状态和关键字过滤
+func (t *tui) applyFilter() {
+	query := strings.ToLower(t.query)
+	t.visible = nil
+	for _, b := range t.all {
+		if t.status == 1 && !b.IsPublished || t.status == 2 && b.IsPublished {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(b.Title), query) &&
+			!strings.Contains(strings.ToLower(strings.Join(b.Tags, " ")), query) {
+			continue
+		}
+		t.visible = append(t.visible, b)
+	}
+	if t.cursor >= len(t.visible) {
+		t.cursor = len(t.visible) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+	t.scroll = 0
+}
+
+func (t *tui) selected() *Blog {
+	if t.cursor < len(t.visible) {
+		return t.visible[t.cursor]
+	}
+	return nil
+}
+
+// 主循环
+func (t *tui) loop() error {
+	for {
+		t.draw()
+		key := t.readKey()
+		t.message = ""
+
+		switch key {
+		case "q", "ctrl-c":
+			return nil
+		case "j", "down":
+			t.move(1)
+		case "k", "up":
+			t.move(-1)
+		case "g":
+			t.move(-len(t.visible))
+		case "G":
+			t.move(len(t.visible))
+		case "J", "pgdn", " ":
+			t.scroll += t.height / 2
+		case "K", "pgup":
+			if t.scroll -= t.height / 2; t.scroll < 0 {
+				t.scroll = 0
+			}
+		case "/":
+			if query, ok := t.prompt("Filter: ", t.query); ok {
+				t.query = query
+				t.applyFilter()
+			}
+		case "f":
+			t.status = (t.status + 1) % len(tuiStatusFilters)
+			t.applyFilter()
+		case "r":
+			if err := t.reload(); err != nil {
+				t.message = err.Error()
+			}
+		case "p":
+			if b := t.selected(); b != nil {
+				updated := *b
+				updated.IsPublished = !b.IsPublished
+				t.save(b, &updated)
+			}
+		case "t":
+			if b := t.selected(); b != nil {
+				if tags, ok := t.prompt("Tags (comma separated): ", strings.Join(b.Tags, ", ")); ok {
+					updated := *b
+					updated.Tags = splitTags(tags)
+					t.save(b, &updated)
+				}
+			}
+		case "e", "enter":
+			if b := t.selected(); b != nil {
+				if err := t.edit(b); err != nil {
+					return err
+				}
+			}
+		case "n":
+			if err := t.edit(&Blog{Title: "Untitled", Content: "\n"}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (t *tui) move(delta int) {
+	t.cursor += delta
+	if t.cursor >= len(t.visible) {
+		t.cursor = len(t.visible) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+	t.scroll = 0
+}
+
+// 保存修改并替换列表中的旧数据
+func (t *tui) save(old, updated *Blog) {
+	saved, err := t.store.Save(updated)
+	if err != nil {
+		t.message = "Save failed: " + err.Error()
+		return
+	}
+	replaced := false
+	for i, b := range t.all {
+		if b == old {
+			t.all[i] = saved
+			replaced = true
+		}
+	}
+	if !replaced {
+		t.all = append([]*Blog{saved}, t.all...)
+	}
+	t.applyFilter()
+	t.message = fmt.Sprintf("Saved #%s (version %d)", saved.ID, saved.Version)
+}
+
+// 使用 $EDITOR 编辑博客（front matter + Markdown），只有终端无法恢复时返回错误
+func (t *tui) edit(b *Blog) error {
+	f, err := os.CreateTemp("", "blog-*.md")
+	if err != nil {
+		t.message = err.Error()
+		return nil
+	}
+	path := f.Name()
+	keep := false
+	defer func() {
+		if !keep {
+			os.Remove(path)
+		}
+	}()
+
+	original := marshalFrontMatter(b)
+	_, err = f.Write(original)
+	f.Close()
+	if err != nil {
+		t.message = err.Error()
+		return nil
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	parts := strings.Fields(editor)
+
+	t.out.WriteString("\x1b[?25h\x1b[?1049l")
+	t.out.Flush()
+	t.restore()
+
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	runErr := cmd.Run()
+
+	if err := t.enterRawMode(); err != nil {
+		// 无法恢复逐键读取时退出界面，保留编辑后的文件
+		t.restore()
+		keep = true
+		return fmt.Errorf("%w (edited file kept at %s)", err, path)
+	}
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+
+	if runErr != nil {
+		t.message = "Editor failed: " + runErr.Error()
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.message = err.Error()
+		return nil
+	}
+	if bytes.Equal(data, original) {
+		t.message = "No changes"
+		return nil
+	}
+
+	updated := *b
+	if err := unmarshalFrontMatter(data, &updated); err != nil {
+		t.message = "Invalid file: " + err.Error()
+		return nil
+	}
+	if updated.Title == "" || strings.TrimSpace(updated.Content) == "" {
+		t.message = "Title and content are required"
+		return nil
+	}
+	// 结构化内容的博客需要同步更新内容块，否则服务端会用旧内容块覆盖正文
+	if len(updated.Blocks) > 0 && updated.Content != b.Content {
+		updated.Blocks = markdownToBlocks(updated.Content)
+	}
+	t.save(b, &updated)
+	return nil
+}
+
+// 拆分逗号分隔的标签
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// 读取一个按键
+func (t *tui) readKey() string {
+	r, _, err := t.in.ReadRune()
+	if err != nil {
+		return "ctrl-c"
+	}
+	switch r {
+	case 3:
+		return "ctrl-c"
+	case '\r', '\n':
+		return "enter"
+	case 127, 8:
+		return "backspace"
+	case 27:
+		if t.in.Buffered() == 0 {
+			return "esc"
+		}
+		seq := make([]byte, 0, 4)
+		for t.in.Buffered() > 0 && len(seq) < 4 {
+			c, _ := t.in.ReadByte()
+			seq = append(seq, c)
+			if c >= 'A' && c <= 'Z' || c == '~' {
+				break
+			}
+		}
+		switch string(seq) {
+		case "[A":
+			return "up"
+		case "[B":
+			return "down"
+		case "[5~":
+			return "pgup"
+		case "[6~":
+			return "pgdn"
+		}
+		return "esc"
+	}
+	return string(r)
+}
+
+// 在底部状态栏读取一行输入，Esc取消
+func (t *tui) prompt(label, initial string) (string, bool) {
+	input := []rune(initial)
+	for {
+		t.out.WriteString(fmt.Sprintf("\x1b[%d;1H\x1b[2K%s%s\x1b[?25h", t.height, label, string(input)))
+		t.out.Flush()
+		key := t.readKey()
+		switch key {
+		case "enter":
+			t.out.WriteString("\x1b[?25l")
+			return strings.TrimSpace(string(input)), true
+		case "esc", "ctrl-c":
+			t.out.WriteString("\x1b[?25l")
+			return "", false
+		case "backspace":
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+		default:
+			if r := []rune(key); len(r) == 1 && r[0] >= 32 {
+				input = append(input, r[0])
+			}
+		}
+	}
+}
+
+// 绘制界面：左侧列表，右侧预览
+func (t *tui) draw() {
+	t.updateSize()
+	listWidth := t.width * 2 / 5
+	previewWidth := t.width - listWidth - 3
+	rows := t.height - 2
+
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+rows {
+		t.offset = t.cursor - rows + 1
+	}
+
+	var preview []string
+	if b := t.selected(); b != nil {
+		preview = t.previewLines(b, previewWidth)
+	}
+	if t.scroll > len(preview)-1 {
+		t.scroll = len(preview) - 1
+	}
+	if t.scroll < 0 {
+		t.scroll = 0
+	}
+
+	t.out.WriteString("\x1b[H\x1b[2J")
+	header := fmt.Sprintf(" %s  %d/%d posts  [%s]", t.store.Name(), len(t.visible), len(t.all), tuiStatusFilters[t.status])
+	if t.query != "" {
+		header += "  /" + t.query
+	}
+	t.out.WriteString("\x1b[7m" + fitWidth(header, t.width) + "\x1b[0m\r\n")
+
+	for i := 0; i < rows; i++ {
+		left := ""
+		idx := t.offset + i
+		if idx < len(t.visible) {
+			b := t.visible[idx]
+			mark := "○"
+			if b.IsPublished {
+				mark = "●"
+			}
+			left = fitWidth(fmt.Sprintf(" %s %s", mark, b.Title), listWidth)
+			if idx == t.cursor {
+				left = "\x1b[7m" + left + "\x1b[0m"
+			}
+		} else {
+			left = fitWidth("", listWidth)
+		}
+
+		right := ""
+		if line := t.scroll + i; line < len(preview) {
+			right = fitWidth(preview[line], previewWidth)
+		}
+		t.out.WriteString(left + " │ " + right + "\r\n")
+	}
+
+	footer := t.message
+	if footer == "" {
+		footer = "j/k move  / filter  f status  e edit  n new  p publish  t tags  J/K scroll  r reload  q quit"
+	}
+	t.out.WriteString("\x1b[7m" + fitWidth(" "+footer, t.width) + "\x1b[0m")
+	t.out.Flush()
+}
+
+// 预览内容：标题、元信息和渲染后的正文
+func (t *tui) previewLines(b *Blog, width int) []string {
+	status := "draft"
+	if b.IsPublished {
+		status = "published"
+	}
+	meta := fmt.Sprintf("#%s  %s  %s", b.ID, status, b.CreatedTime.Format("2006-01-02 15:04"))
+	if len(b.Tags) > 0 {
+		meta += "  #" + strings.Join(b.Tags, " #")
+	}
+
+	var lines []string
+	for _, line := range append([]string{b.Title, meta, ""}, strings.Split(renderBlogText(b), "\n")...) {
+		lines = append(lines, wrapWidth(line, width)...)
+	}
+	return lines
+}
+
+// 截断或补齐到指定显示宽度
+func fitWidth(s string, width int) string {
+	var sb strings.Builder
+	w := 0
+	for _, r := range s {
+		if r == '\t' {
+			r = ' '
+		}
+		rw := runeWidth(r)
+		if w+rw > width {
+			break
+		}
+		sb.WriteRune(r)
+		w += rw
+	}
+	return sb.String() + strings.Repeat(" ", width-w)
+}
+
+// 按显示宽度折行
+func wrapWidth(s string, width int) []string {
+	if width <= 0 || textWidth(s) <= width {
+		return []string{s}
+	}
+	var lines []string
+	var line strings.Builder
+	w := 0
+	for _, r := range s {
+		rw := runeWidth(r)
+		if w+rw > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			w = 0
+		}
+		line.WriteRune(r)
+		w += rw
+	}
+	return append(lines, line.String())
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// LinkPreview 链接预览卡片
+type LinkPreview struct {
+	URL         string    `json:"url"`                   // 最终抓取的地址（跟随重定向后）
+	Title       string    `json:"title,omitempty"`       // 标题
+	Description string    `json:"description,omitempty"` // 描述
+	Image       string    `json:"image,omitempty"`       // OpenGraph 图片
+	SiteName    string    `json:"site_name,omitempty"`   // 站点名称
+	FetchedAt   time.Time `json:"fetched_at"`            // 抓取时间
+}
+
+// unfurler 抓取网页并提取预览信息
+type unfurler struct {
+	client    *http.Client
+	maxBytes  int64
+	mu        sync.Mutex
+	cache     map[string]*LinkPreview // 地址 -> 最近一次抓取结果
+	cacheSize int                     // 缓存数量上限
+	cacheTTL  time.Duration           // 超过该时间的缓存在写入新结果时清除
+}
+
+var (
+	linkUnfurler     *unfurler
+	linkUnfurlerOnce sync.Once
+)
+
+// 默认的链接预览抓取器（按配置初始化）
+func defaultUnfurler() *unfurler {
+	linkUnfurlerOnce.Do(func() {
+		linkUnfurler = newUnfurler(config.Unfurl)
+	})
+	return linkUnfurler
+}
+
+func newUnfurler(cfg UnfurlConfig) *unfurler {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !cfg.AllowPrivate {
+		dialer.Control = denyPrivateAddress
+	}
+	transport := &http.Transport{
+		// 不使用环境变量中的代理，否则地址检查只会作用于代理本身
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: time.Duration(cfg.TimeoutSeconds) * time.Second,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	return &unfurler{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 5 {
+					return fmt.Errorf("too many redirects")
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+		maxBytes:  cfg.MaxBytes,
+		cache:     make(map[string]*LinkPreview),
+		cacheSize: cfg.CacheEntries,
+		cacheTTL:  time.Duration(cfg.RefreshHours) * time.Hour,
+	}
+}
+
+// 拒绝连接内网、回环、链路本地等地址，防止SSRF（在DNS解析之后检查，可防御DNS重绑定）
+func denyPrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("refusing to connect to non-public address %s", host)
+	}
+	return nil
+}
+
+// 运营商级NAT等不属于 net.IP.IsPrivate 的保留网段
+var reservedNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{"0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4", "64:ff9b::/96"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fetch 抓取链接预览，maxAge 内的缓存结果直接返回
+func (u *unfurler) Fetch(ctx context.Context, rawURL string, maxAge time.Duration) (*LinkPreview, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("link must be an absolute http(s) URL")
+	}
+
+	u.mu.Lock()
+	cached, ok := u.cache[rawURL]
+	u.mu.Unlock()
+	if ok && time.Since(cached.FetchedAt) < maxAge {
+		preview := *cached
+		return &preview, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "BlogLinkPreview/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: status %d", rawURL, resp.StatusCode)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+
+	// 只读取文档开头，元信息一般位于 <head> 中
+	body, err := io.ReadAll(io.LimitReader(resp.Body, u.maxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+
+	preview := extractPreview(string(body), resp.Request.URL)
+	preview.FetchedAt = time.Now().UTC()
+
+	u.store(rawURL, preview)
+
+	result := *preview
+	return &result, nil
+}
+
+// 写入缓存：先清除过期的结果，仍然超过上限时清除最早抓取的结果
+func (u *unfurler) store(rawURL string, preview *LinkPreview) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.cacheSize <= 0 {
+		return
+	}
+	if _, ok := u.cache[rawURL]; !ok && len(u.cache) >= u.cacheSize {
+		for k, v := range u.cache {
+			if time.Since(v.FetchedAt) >= u.cacheTTL {
+				delete(u.cache, k)
+			}
+		}
+		for len(u.cache) >= u.cacheSize {
+			oldest := ""
+			for k, v := range u.cache {
+				if oldest == "" || v.FetchedAt.Before(u.cache[oldest].FetchedAt) {
+					oldest = k
+				}
+			}
+			delete(u.cache, oldest)
+		}
+	}
+	u.cache[rawURL] = preview
+}
+
+var (
+	htmlMetaTag   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	htmlAttr      = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*("([^"]*)"|'([^']*)')`)
+	htmlTitleTag  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlSpaceRuns = regexp.MustCompile(`\s+`)
+)
+
+// 从HTML中提取 OpenGraph / Twitter Card / 标准元信息
+func extractPreview(doc string, base *url.URL) *LinkPreview {
+	meta := make(map[string]string)
+	for _, tag := range htmlMetaTag.FindAllString(doc, -1) {
+		attrs := make(map[string]string)
+		for _, m := range htmlAttr.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = m[3] + m[4]
+		}
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(key)
+		if key != "" && meta[key] == "" {
+			meta[key] = cleanPreviewText(attrs["content"], 500)
+		}
+	}
+
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v := meta[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	preview := &LinkPreview{
+		URL:         base.String(),
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		SiteName:    first("og:site_name", "application-name"),
+	}
+	if preview.Title == "" {
+		if m := htmlTitleTag.FindStringSubmatch(doc); m != nil {
+			preview.Title = cleanPreviewText(m[1], 300)
+		}
+	}
+	if preview.SiteName == "" {
+		preview.SiteName = base.Hostname()
+	}
+	if image := first("og:image", "og:image:url", "twitter:image"); image != "" {
+		if ref, err := url.Parse(image); err == nil {
+			if abs := base.ResolveReference(ref); abs.Scheme == "http" || abs.Scheme == "https" {
+				preview.Image = abs.String()
+			}
+		}
+	}
+	return preview
+}
+
+// 解码实体、合并空白并限制长度
+func cleanPreviewText(s string, maxRunes int) string {
+	s = strings.TrimSpace(htmlSpaceRuns.ReplaceAllString(html.UnescapeString(s), " "))
+	if runes := []rune(s); len(runes) > maxRunes {
+		s = string(runes[:maxRunes]) + "…"
+	}
+	return s
+}
+
+// 预览缓存的有效期
+func previewMaxAge() time.Duration {
+	return time.Duration(config.Unfurl.RefreshHours) * time.Hour
+}
+
+// 保存前为链接博客准备预览：沿用同一地址的未过期预览，否则保存后由后台抓取
+func prepareLinkPreview(old, blog *Blog) {
+	// 预览只由服务端生成，忽略客户端提交的内容
+	blog.LinkPreview = nil
+	if blog.LinkURL == "" {
+		return
+	}
+	if old != nil && old.LinkURL == blog.LinkURL && old.LinkPreview != nil &&
+		time.Since(old.LinkPreview.FetchedAt) < previewMaxAge() {
+		blog.LinkPreview = old.LinkPreview
+	}
+}
+
+// 等待后台抓取预览的博客；队列已满时由定期刷新补上
+var previewQueue = make(chan BlogID, 100)
+
+// 保存后将缺少预览的链接博客加入抓取队列，保存不等待远程服务器
+func queueLinkPreview(old, blog *Blog) {
+	if blog.LinkURL == "" || blog.LinkPreview != nil {
+		return
+	}
+	select {
+	case previewQueue <- blog.ID:
+	default:
+	}
+}
+
+func init() {
+	onBlogSaved(queueLinkPreview)
+}
+
+// 刷新博客的链接预览（不改变版本号）：抓取后重新读取博客，只更新预览，不覆盖抓取期间的修改；
+// maxAge 为可沿用的缓存结果的最长时间，0 表示强制重新抓取
+func refreshLinkPreview(blog *Blog, maxAge time.Duration) error {
+	if blog.LinkURL == "" {
+		return fmt.Errorf("blog has no link")
+	}
+	preview, err := defaultUnfurler().Fetch(context.Background(), blog.LinkURL, maxAge)
+	if err != nil {
+		return err
+	}
+	latest, err := updateBlog(blog.ID, func(b *Blog) error {
+		if b.LinkURL != blog.LinkURL {
+			return fmt.Errorf("link changed while fetching the preview")
+		}
+		b.LinkPreview = preview
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*blog = *latest
+	pageCache.Invalidate(blogDep(blog.ID), "index")
+	return nil
+}
+
+// 后台抓取保存时加入队列的预览，并定期刷新过期的链接预览
+func startPreviewRefresher(interval time.Duration) {
+	go func() {
+		tick := time.Tick(interval)
+		for {
+			select {
+			case id := <-previewQueue:
+				b, err := LoadBlog(id)
+				if err != nil || b.LinkURL == "" || b.LinkPreview != nil {
+					continue
+				}
+				if err := refreshLinkPreview(b, previewMaxAge()); err != nil {
+					log.Printf("Failed to unfurl %s for blog %s: %v", b.LinkURL, b.ID, err)
+				}
+			case <-tick:
+				blogs, err := ListBlogs()
+				if err != nil {
+					log.Printf("Failed to list blogs for preview refresh: %v", err)
+					continue
+				}
+				for _, b := range blogs {
+					if b.LinkURL == "" || b.LinkPreview != nil && time.Since(b.LinkPreview.FetchedAt) < previewMaxAge() {
+						continue
+					}
+					if err := refreshLinkPreview(b, 0); err != nil {
+						log.Printf("Failed to refresh link preview for blog %s: %v", b.ID, err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+var blogUnfurlPath = regexp.MustCompile("^/api/blogs/([0-9A-Za-z_-]+)/unfurl$")
+
+// 立即刷新链接预览：POST /api/blogs/{id}/unfurl（需要API密钥）
+func unfurlHandler(w http.ResponseWriter, r *http.Request) {
+	if requestAPIKey(r) == nil {
+		sendResponse(w, false, "", nil, "API key required", http.StatusUnauthorized)
+		return
+	}
+	matches := blogUnfurlPath.FindStringSubmatch(r.URL.Path)
+	id, err := parseBlogID(matches[1])
+	if err != nil {
+		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+	blog, err := LoadBlog(id)
+	if err != nil {
+		sendResponse(w, false, "", nil, "Blog not found", http.StatusNotFound)
+		return
+	}
+	if err := refreshLinkPreview(blog, 0); err != nil {
+		sendResponse(w, false, "", nil, "Failed to unfurl link: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	sendResponse(w, true, "Link preview refreshed", blog.LinkPreview, "", http.StatusOK)
+}
+
+// 编辑器粘贴链接时获取预览（不保存）：GET /api/unfurl?url=...（需要API密钥，避免被当作公开的抓取代理）
+func linkPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if requestAPIKey(r) == nil {
+		sendResponse(w, false, "", nil, "API key required", http.StatusUnauthorized)
+		return
+	}
+	link := r.URL.Query().Get("url")
+	if !isWebURL(link) {
+		sendResponse(w, false, "", nil, "url must be an http(s) URL", http.StatusBadRequest)
+		return
+	}
+	preview, err := defaultUnfurler().Fetch(r.Context(), link, previewMaxAge())
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to unfurl link: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	sendResponse(w, true, "Link preview retrieved successfully", preview, "", http.StatusOK)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testUnfurler(allowPrivate bool, maxBytes int64) *unfurler {
+	return newUnfurler(UnfurlConfig{TimeoutSeconds: 5, MaxBytes: maxBytes, RefreshHours: 1, CacheEntries: 10, AllowPrivate: allowPrivate})
+}
+
+func TestUnfurlFollowsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><meta property="og:title" content="New &amp; shiny"><meta property="og:image" content="/cover.png"></head></html>`)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/ftp", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "ftp://example.com/file", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	u := testUnfurler(true, 1<<20)
+	preview, err := u.Fetch(context.Background(), srv.URL+"/old", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.URL != srv.URL+"/new" || preview.Title != "New & shiny" || preview.Image != srv.URL+"/cover.png" {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+
+	if _, err := u.Fetch(context.Background(), srv.URL+"/loop", time.Hour); err == nil || !strings.Contains(err.Error(), "too many redirects") {
+		t.Errorf("redirect loop: got %v", err)
+	}
+	if _, err := u.Fetch(context.Background(), srv.URL+"/ftp", time.Hour); err == nil || !strings.Contains(err.Error(), "unsupported scheme") {
+		t.Errorf("redirect to ftp: got %v", err)
+	}
+}
+
+func TestUnfurlReadsAtMostMaxBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<title>Head</title>`+strings.Repeat(" ", 200)+`<meta name="description" content="beyond the limit">`)
+	}))
+	defer srv.Close()
+
+	preview, err := testUnfurler(true, 100).Fetch(context.Background(), srv.URL, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Title != "Head" || preview.Description != "" {
+		t.Errorf("expected only the first 100 bytes to be parsed, got %+v", preview)
+	}
+}
+
+func TestUnfurlRejectsNonHTML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("binary"))
+	}))
+	defer srv.Close()
+
+	if _, err := testUnfurler(true, 1<<20).Fetch(context.Background(), srv.URL, time.Hour); err == nil {
+		t.Error("expected an error for a non-HTML response")
+	}
+}
+
+func TestUnfurlBlocksPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<title>internal</title>`)
+	}))
+	defer srv.Close()
+
+	_, err := testUnfurler(false, 1<<20).Fetch(context.Background(), srv.URL, time.Hour)
+	if err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("expected loopback address to be refused, got %v", err)
+	}
+
+	// 公网地址的重定向目标指向内网时同样被拒绝：拨号检查作用于每一跳
+	for _, addr := range []string{"10.0.0.1", "169.254.169.254", "100.64.0.1", "::1", "fd00::1"} {
+		if err := denyPrivateAddress("tcp", fmt.Sprintf("[%s]:80", addr), nil); err == nil {
+			t.Errorf("%s should be refused", addr)
+		}
+	}
+	if err := denyPrivateAddress("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("public address refused: %v", err)
+	}
+}
+
+func TestUnfurlCacheIsBounded(t *testing.T) {
+	u := testUnfurler(true, 1<<20)
+	now := time.Now()
+	for i := 0; i < 25; i++ {
+		u.store(fmt.Sprintf("https://example.com/%d", i), &LinkPreview{FetchedAt: now.Add(time.Duration(i) * time.Second)})
+	}
+	if len(u.cache) != 10 {
+		t.Fatalf("cache has %d entries, want 10", len(u.cache))
+	}
+	if _, ok := u.cache["https://example.com/24"]; !ok {
+		t.Error("newest entry was evicted")
+	}
+	if _, ok := u.cache["https://example.com/0"]; ok {
+		t.Error("oldest entry was kept")
+	}
+
+	// 过期的结果在写入时清除
+	u.store("https://example.com/stale", &LinkPreview{FetchedAt: now.Add(-2 * time.Hour)})
+	u.store("https://example.com/fresh", &LinkPreview{FetchedAt: now})
+	if _, ok := u.cache["https://example.com/stale"]; ok {
+		t.Error("expired entry was kept")
+	}
+}
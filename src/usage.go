@@ -0,0 +1,586 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 使用量统计目录，每天一个文件，保存当天的小时桶
+const usageDir = "data/usage"
+
+// 匿名请求在统计中使用的密钥ID，无效密钥的请求记为 invalid:<IP>
+const (
+	anonymousKeyID     = "anonymous"
+	invalidKeyIDPrefix = "invalid:"
+)
+
+func init() {
+	if err := os.MkdirAll(usageDir, 0755); err != nil {
+		log.Fatalf("Failed to create usage directory: %v", err)
+	}
+}
+
+// usageBucket 某个密钥在某小时内对某路由的使用量
+type usageBucket struct {
+	Hour     time.Time `json:"hour"`
+	KeyID    string    `json:"key_id"`
+	UserID   int       `json:"user_id,omitempty"`
+	Route    string    `json:"route"`
+	Requests int64     `json:"requests"`
+	Errors   int64     `json:"errors"`             // 状态码 >= 400 的请求
+	Rejected int64     `json:"rejected,omitempty"` // 被限流或超出配额而拒绝的请求
+	BytesIn  int64     `json:"bytes_in"`
+	BytesOut int64     `json:"bytes_out"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+type usageBucketKey struct {
+	hour  time.Time
+	keyID string
+	route string
+}
+
+// usageStore 内存中累计使用量，定期写入按天划分的文件
+type usageStore struct {
+	mu      sync.Mutex
+	buckets map[usageBucketKey]*usageBucket
+	daily   map[string]int64 // 当天（UTC）每个密钥的请求数，用于配额
+	day     string
+}
+
+var usage = newUsageStore()
+
+func newUsageStore() *usageStore {
+	return &usageStore{
+		buckets: make(map[usageBucketKey]*usageBucket),
+		daily:   make(map[string]int64),
+		day:     time.Now().UTC().Format("2006-01-02"),
+	}
+}
+
+// 从当天的统计文件恢复计数（服务重启后配额不会被重置）
+func (s *usageStore) load() error {
+	buckets, err := readUsageDay(s.day)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range buckets {
+		b := buckets[i]
+		s.buckets[usageBucketKey{b.Hour, b.KeyID, b.Route}] = &b
+		s.daily[b.KeyID] += b.Requests - b.Rejected
+	}
+	return nil
+}
+
+// 记录一次请求，被限流或超出配额而拒绝的请求（rejected）不计入配额
+func (s *usageStore) record(keyID string, userID int, route string, status int, bytesIn, bytesOut int64, t time.Time, rejected bool) {
+	t = t.UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if day := t.Format("2006-01-02"); day != s.day {
+		s.day = day
+		s.daily = make(map[string]int64)
+	}
+	if !rejected {
+		s.daily[keyID]++
+	}
+
+	k := usageBucketKey{t.Truncate(time.Hour), keyID, route}
+	b, ok := s.buckets[k]
+	if !ok {
+		b = &usageBucket{Hour: k.hour, KeyID: keyID, UserID: userID, Route: route}
+		s.buckets[k] = b
+	}
+	b.Requests++
+	if status >= 400 {
+		b.Errors++
+	}
+	if rejected {
+		b.Rejected++
+	}
+	b.BytesIn += bytesIn
+	b.BytesOut += bytesOut
+	b.LastSeen = t
+}
+
+// 密钥当天已使用的请求数
+func (s *usageStore) today(keyID string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.day != time.Now().UTC().Format("2006-01-02") {
+		return 0
+	}
+	return s.daily[keyID]
+}
+
+// 将内存中的小时桶写入文件，已结束日期的数据写入成功后才从内存释放，写入失败时留待下次重试
+func (s *usageStore) flush() error {
+	s.mu.Lock()
+	byDay := make(map[string][]usageBucket)
+	for _, b := range s.buckets {
+		day := b.Hour.Format("2006-01-02")
+		byDay[day] = append(byDay[day], *b)
+	}
+	s.mu.Unlock()
+
+	for day, buckets := range byDay {
+		sort.Slice(buckets, func(i, j int) bool {
+			if !buckets[i].Hour.Equal(buckets[j].Hour) {
+				return buckets[i].Hour.Before(buckets[j].Hour)
+			}
+			if buckets[i].KeyID != buckets[j].KeyID {
+				return buckets[i].KeyID < buckets[j].KeyID
+			}
+			return buckets[i].Route < buckets[j].Route
+		})
+		data, err := json.MarshalIndent(buckets, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal usage: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(usageDir, day+".json"), data, 0644); err != nil {
+			return fmt.Errorf("failed to write usage file: %w", err)
+		}
+		s.release(day)
+	}
+	return nil
+}
+
+// 释放已结束日期的小时桶，当天的数据继续留在内存中累计
+func (s *usageStore) release(day string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if day == s.day {
+		return
+	}
+	for k := range s.buckets {
+		if k.hour.Format("2006-01-02") == day {
+			delete(s.buckets, k)
+		}
+	}
+}
+
+func readUsageDay(day string) ([]usageBucket, error) {
+	data, err := os.ReadFile(filepath.Join(usageDir, day+".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
+	}
+	var buckets []usageBucket
+	if err := json.Unmarshal(data, &buckets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal usage: %w", err)
+	}
+	return buckets, nil
+}
+
+// 删除超过保留天数的统计文件
+func pruneUsage(retentionDays int) {
+	if retentionDays <= 0 {
+		return
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -retentionDays).Format("2006-01-02")
+	files, err := os.ReadDir(usageDir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		if day := strings.TrimSuffix(f.Name(), ".json"); day < cutoff {
+			os.Remove(filepath.Join(usageDir, f.Name()))
+		}
+	}
+}
+
+// 启动使用量统计：恢复当天计数并每分钟写盘
+func startUsageTracking() {
+	if err := usage.load(); err != nil {
+		log.Printf("Failed to load usage: %v", err)
+	}
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := usage.flush(); err != nil {
+				log.Printf("Failed to flush usage: %v", err)
+			}
+			limiter.sweep(time.Now())
+			pruneUsage(config.Usage.RetentionDays)
+		}
+	}()
+}
+
+// rateLimiter 按密钥（匿名请求按IP）的令牌桶限流
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+var limiter = &rateLimiter{buckets: make(map[string]*tokenBucket)}
+
+// 尝试消耗一个令牌，返回是否允许、剩余令牌数和需要等待的时长
+func (l *rateLimiter) allow(subject string, perMinute int, now time.Time) (bool, int, time.Duration) {
+	if perMinute <= 0 {
+		return true, -1, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	capacity := float64(perMinute)
+	b, ok := l.buckets[subject]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, last: now}
+		l.buckets[subject] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Minutes()*capacity)
+	b.last = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / capacity * float64(time.Minute))
+		return false, 0, wait
+	}
+	b.tokens--
+	return true, int(b.tokens), 0
+}
+
+// 是否已没有可用令牌（不消耗令牌）
+func (l *rateLimiter) exhausted(subject string, perMinute int, now time.Time) bool {
+	if perMinute <= 0 {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[subject]
+	return ok && b.tokens+now.Sub(b.last).Minutes()*float64(perMinute) < 1
+}
+
+// 清除空闲超过一分钟的令牌桶：令牌已回满，与新建的桶相同
+func (l *rateLimiter) sweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for subject, b := range l.buckets {
+		if now.Sub(b.last) >= time.Minute {
+			delete(l.buckets, subject)
+		}
+	}
+}
+
+// 统计路由时将ID、slug等可变部分归一化，避免按具体地址分散：
+// 只保留API路由中的固定路径，其余路径段记为 {id}（带表示扩展名时为 {id}.md 等）
+var (
+	usageNamedPaths = []string{"/api/pages/", "/api/menus/", "/api/authors/"}
+	usageRouteWords = map[string]bool{
+		"api": true, "blogs": true, "unfurl": true, "xliff": true, "references": true, "comments": true, "revisions": true,
+		"admin": true, "usage": true, "search": true, "click": true, "report": true, "synonyms": true,
+		"users": true, "import": true, "invitations": true, "accept": true, "planet": true, "sources": true, "fetch": true,
+		"promote": true, "export": true, "apply": true, "visibility": true, "me": true, "follows": true, "feed": true,
+		"feed-token": true, "signing-key": true, "verify": true, "blocks": true, "from-markdown": true, "to-markdown": true,
+		"bibliography": true, "pages": true, "menus": true, "authors": true,
+	}
+	usageExtSegment = regexp.MustCompile(`^[^.]+\.(md|txt|html|json)$`)
+)
+
+func usageRoute(method, path string) string {
+	for _, prefix := range usageNamedPaths {
+		if strings.HasPrefix(path, prefix) && len(path) > len(prefix) {
+			return method + " " + prefix + "{name}"
+		}
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if seg == "" || usageRouteWords[seg] {
+			continue
+		}
+		if m := usageExtSegment.FindStringSubmatch(seg); m != nil {
+			segments[i] = "{id}." + m[1]
+		} else {
+			segments[i] = "{id}"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+// usageRecorder 记录响应状态码和字节数
+type usageRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rec *usageRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *usageRecorder) Write(p []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(p)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// countingReader 统计请求体字节数
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// API中间件：识别密钥、限流、检查每日配额并记录使用量
+func usageMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+
+		var key *APIKey
+		if token := requestToken(r); token != "" {
+			// 无效密钥按IP限流并记录，超出限制后不再读取密钥文件
+			ip := clientIP(r)
+			subject, rate := "invalid:"+ip, config.Usage.InvalidKeyRatePerMinute
+			if limiter.exhausted(subject, rate, start) {
+				usage.record(invalidKeyIDPrefix+ip, 0, usageRoute(r.Method, r.URL.Path), http.StatusTooManyRequests, 0, 0, start, true)
+				sendResponse(w, false, "", nil, "Too many invalid API key attempts", http.StatusTooManyRequests)
+				return
+			}
+			found, err := findAPIKey(token)
+			if err != nil {
+				log.Printf("Failed to look up API key: %v", err)
+				sendResponse(w, false, "", nil, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			if found == nil {
+				limiter.allow(subject, rate, start)
+				usage.record(invalidKeyIDPrefix+ip, 0, usageRoute(r.Method, r.URL.Path), http.StatusUnauthorized, 0, 0, start, false)
+				sendResponse(w, false, "", nil, "Invalid API key", http.StatusUnauthorized)
+				return
+			}
+			key = found
+		}
+
+		keyID, userID, subject := anonymousKeyID, 0, "ip:"+clientIP(r)
+		rate, quota := config.Usage.AnonymousRatePerMinute, 0
+		if key != nil {
+			keyID, userID, subject = key.ID, key.UserID, "key:"+key.ID
+			rate, quota = config.Usage.RatePerMinute, config.Usage.DailyQuota
+			if key.RatePerMinute > 0 {
+				rate = key.RatePerMinute
+			}
+			if key.DailyQuota > 0 {
+				quota = key.DailyQuota
+			}
+		}
+
+		rec := &usageRecorder{ResponseWriter: w, status: http.StatusOK}
+		body := &countingReader{ReadCloser: r.Body}
+		r.Body = body
+		route := usageRoute(r.Method, r.URL.Path)
+		rejected := true
+		defer func() {
+			usage.record(keyID, userID, route, rec.status, body.n, rec.bytes, start, rejected)
+		}()
+
+		allowed, remaining, wait := limiter.allow(subject, rate, start)
+		if rate > 0 {
+			rec.Header().Set("X-RateLimit-Limit", strconv.Itoa(rate))
+			rec.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		}
+		if !allowed {
+			rec.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			sendResponse(rec, false, "", nil, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		if quota > 0 {
+			used := usage.today(keyID)
+			if used >= int64(quota) {
+				tomorrow := start.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+				rec.Header().Set("Retry-After", strconv.Itoa(int(tomorrow.Sub(start).Seconds())+1))
+				sendResponse(rec, false, "", nil, "Daily quota exceeded", http.StatusTooManyRequests)
+				return
+			}
+			rec.Header().Set("X-Quota-Remaining", strconv.FormatInt(int64(quota)-used-1, 10))
+		}
+
+		rejected = false
+		next.ServeHTTP(rec, withAPIKey(r, key))
+	})
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// usageSummary 某密钥或用户在统计区间内的汇总
+type usageSummary struct {
+	KeyID     string                 `json:"key_id,omitempty"`
+	Name      string                 `json:"name,omitempty"`
+	UserID    int                    `json:"user_id"`
+	Requests  int64                  `json:"requests"`
+	Errors    int64                  `json:"errors"`
+	ErrorRate float64                `json:"error_rate"`
+	BytesIn   int64                  `json:"bytes_in"`
+	BytesOut  int64                  `json:"bytes_out"`
+	LastSeen  time.Time              `json:"last_seen"`
+	Routes    map[string]*routeUsage `json:"routes"`
+}
+
+type routeUsage struct {
+	Requests int64 `json:"requests"`
+	Errors   int64 `json:"errors"`
+}
+
+func (s *usageSummary) add(b usageBucket) {
+	s.Requests += b.Requests
+	s.Errors += b.Errors
+	s.BytesIn += b.BytesIn
+	s.BytesOut += b.BytesOut
+	if b.LastSeen.After(s.LastSeen) {
+		s.LastSeen = b.LastSeen
+	}
+	r, ok := s.Routes[b.Route]
+	if !ok {
+		r = &routeUsage{}
+		s.Routes[b.Route] = r
+	}
+	r.Requests += b.Requests
+	r.Errors += b.Errors
+	s.ErrorRate = float64(s.Errors) / float64(s.Requests)
+}
+
+// usageReport 使用量报告
+type usageReport struct {
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Keys    []*usageSummary `json:"keys"`
+	Users   []*usageSummary `json:"users"`
+	Buckets []usageBucket   `json:"buckets"` // 小时桶明细
+}
+
+// 解析日期（YYYY-MM-DD）或RFC3339时间
+func parseUsageTime(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// 使用量报告：GET /api/admin/usage?from=&to=&key=&user=（需要管理员密钥）
+func usageHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	to := time.Now().UTC()
+	from := to.Add(-24 * time.Hour)
+	var err error
+	if v := query.Get("from"); v != "" {
+		if from, err = parseUsageTime(v); err != nil {
+			sendResponse(w, false, "", nil, "Invalid from", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if to, err = parseUsageTime(v); err != nil {
+			sendResponse(w, false, "", nil, "Invalid to", http.StatusBadRequest)
+			return
+		}
+	}
+	if !from.Before(to) || to.Sub(from) > 366*24*time.Hour {
+		sendResponse(w, false, "", nil, "from must be before to and within one year", http.StatusBadRequest)
+		return
+	}
+	userFilter := -1
+	if v := query.Get("user"); v != "" {
+		if userFilter, err = strconv.Atoi(v); err != nil {
+			sendResponse(w, false, "", nil, "Invalid user", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := usage.flush(); err != nil {
+		log.Printf("Failed to flush usage: %v", err)
+	}
+	keys, err := loadAPIKeys()
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to load API keys", http.StatusInternalServerError)
+		return
+	}
+	names := make(map[string]string)
+	for _, k := range keys {
+		names[k.ID] = k.Name
+	}
+
+	report := usageReport{From: from, To: to, Keys: []*usageSummary{}, Users: []*usageSummary{}, Buckets: []usageBucket{}}
+	byKey := make(map[string]*usageSummary)
+	byUser := make(map[int]*usageSummary)
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(to); day = day.Add(24 * time.Hour) {
+		buckets, err := readUsageDay(day.Format("2006-01-02"))
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to read usage", http.StatusInternalServerError)
+			return
+		}
+		for _, b := range buckets {
+			if b.Hour.Add(time.Hour).Before(from) || !b.Hour.Before(to) {
+				continue
+			}
+			if k := query.Get("key"); k != "" && b.KeyID != k || userFilter >= 0 && b.UserID != userFilter {
+				continue
+			}
+			report.Buckets = append(report.Buckets, b)
+
+			ks, ok := byKey[b.KeyID]
+			if !ok {
+				ks = &usageSummary{KeyID: b.KeyID, Name: names[b.KeyID], UserID: b.UserID, Routes: map[string]*routeUsage{}}
+				byKey[b.KeyID] = ks
+				report.Keys = append(report.Keys, ks)
+			}
+			ks.add(b)
+			if b.KeyID == anonymousKeyID {
+				continue
+			}
+			us, ok := byUser[b.UserID]
+			if !ok {
+				us = &usageSummary{UserID: b.UserID, Routes: map[string]*routeUsage{}}
+				byUser[b.UserID] = us
+				report.Users = append(report.Users, us)
+			}
+			us.add(b)
+		}
+	}
+	sort.Slice(report.Keys, func(i, j int) bool { return report.Keys[i].Requests > report.Keys[j].Requests })
+	sort.Slice(report.Users, func(i, j int) bool { return report.Users[i].Requests > report.Users[j].Requests })
+
+	sendResponse(w, true, "Usage retrieved successfully", report, "", http.StatusOK)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestUsageRouteNormalizesVariableSegments(t *testing.T) {
+	cases := map[string]string{
+		"/api/blogs/1":                          "GET /api/blogs/{id}",
+		"/api/blogs/01HZX3K8Q9V7W2M4N6P8R0T2Y4": "GET /api/blogs/{id}",
+		"/api/blogs/1.md":                       "GET /api/blogs/{id}.md",
+		"/api/blogs/some-slug/revisions/3":      "GET /api/blogs/{id}/revisions/{id}",
+		"/api/bibliography/knuth1984":           "GET /api/bibliography/{id}",
+		"/api/admin/planet/sources":             "GET /api/admin/planet/sources",
+		"/api/pages/about/team":                 "GET /api/pages/{name}",
+		"/api/no-such-route/x.y.z":              "GET /api/{id}/{id}",
+	}
+	for path, want := range cases {
+		if got := usageRoute(http.MethodGet, path); got != want {
+			t.Errorf("usageRoute(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestInvalidKeysAreRateLimitedByIP(t *testing.T) {
+	saved := config.Usage
+	defer func() { config.Usage = saved }()
+	config.Usage.InvalidKeyRatePerMinute = 3
+
+	handler := usageMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	var codes []int
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/blogs", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		req.Header.Set("X-API-Key", "blog_not_a_real_key")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	want := []int{401, 401, 401, 429, 429}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("status codes %v, want %v", codes, want)
+		}
+	}
+	if n := usage.today(invalidKeyIDPrefix + "203.0.113.7"); n != 3 {
+		t.Errorf("recorded %d invalid attempts, want 3", n)
+	}
+}
+
+func TestRateLimiterSweepsIdleBuckets(t *testing.T) {
+	l := &rateLimiter{buckets: make(map[string]*tokenBucket)}
+	now := time.Now()
+	l.allow("ip:a", 10, now.Add(-2*time.Minute))
+	l.allow("ip:b", 10, now)
+	l.sweep(now)
+	if _, ok := l.buckets["ip:a"]; ok {
+		t.Error("idle bucket was kept")
+	}
+	if _, ok := l.buckets["ip:b"]; !ok {
+		t.Error("active bucket was removed")
+	}
+}
+
+func TestUsageFlushKeepsPreviousDayUntilWritten(t *testing.T) {
+	useTestSite(t)
+	s := newUsageStore()
+	now := time.Now().UTC()
+	yesterday := now.Add(-24 * time.Hour)
+	s.record(anonymousKeyID, 0, "GET /api/blogs", 200, 0, 100, yesterday, false)
+	s.record(anonymousKeyID, 0, "GET /api/blogs", 200, 0, 100, now, false)
+
+	// 用同名文件占住目录，使写入失败
+	if err := os.RemoveAll(usageDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(usageDir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.flush(); err == nil {
+		t.Fatal("flush succeeded without a usage directory")
+	}
+	if len(s.buckets) != 2 {
+		t.Fatalf("%d bucket(s) left after a failed flush, want 2", len(s.buckets))
+	}
+
+	if err := os.Remove(usageDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(usageDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.buckets) != 1 {
+		t.Errorf("%d bucket(s) left after flushing, want only today's", len(s.buckets))
+	}
+	buckets, err := readUsageDay(yesterday.Format("2006-01-02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) != 1 || buckets[0].Requests != 1 {
+		t.Errorf("previous day on disk: %+v", buckets)
+	}
+}
@@ -0,0 +1,688 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// User 站点用户，通过邀请链接领取API密钥
+type User struct {
+	ID          int        `json:"id"`
+	Email       string     `json:"email"`                  // 邮箱（唯一，统一小写）
+	DisplayName string     `json:"display_name"`           // 显示名称
+	Role        string     `json:"role"`                   // 角色，见 userRoles
+	AuthorID    int        `json:"author_id,omitempty"`    // 对应的作者资料
+	CreatedTime time.Time  `json:"created_at"`             // 创建时间
+	InvitedAt   *time.Time `json:"invited_at,omitempty"`   // 最近一次发送邀请的时间
+	ActivatedAt *time.Time `json:"activated_at,omitempty"` // 接受邀请的时间
+}
+
+// 用户角色：viewer 以外的角色会关联作者资料
+var userRoles = map[string]bool{"admin": true, "editor": true, "author": true, "viewer": true}
+
+// invitation 邀请记录，只保存令牌的摘要
+type invitation struct {
+	Hash         string     `json:"hash"`
+	UserID       int        `json:"user_id"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at,omitempty"`
+	SupersededAt *time.Time `json:"superseded_at,omitempty"` // 重新邀请后旧的邀请失效
+}
+
+const (
+	userFile       = "data/users.json"
+	invitationFile = "data/invitations.json"
+)
+
+var usersMu sync.Mutex
+
+// 加载全部用户，文件不存在时返回空列表
+func loadUsers() ([]User, error) {
+	data, err := os.ReadFile(userFile)
+	if os.IsNotExist(err) {
+		return []User{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user file: %w", err)
+	}
+	var users []User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal users: %w", err)
+	}
+	return users, nil
+}
+
+func saveUsers(users []User) error {
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal users: %w", err)
+	}
+	if err := os.WriteFile(userFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write user file: %w", err)
+	}
+	return nil
+}
+
+func loadInvitations() ([]invitation, error) {
+	data, err := os.ReadFile(invitationFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read invitation file: %w", err)
+	}
+	var invites []invitation
+	if err := json.Unmarshal(data, &invites); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal invitations: %w", err)
+	}
+	return invites, nil
+}
+
+func saveInvitations(invites []invitation) error {
+	data, err := json.MarshalIndent(invites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal invitations: %w", err)
+	}
+	if err := os.WriteFile(invitationFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write invitation file: %w", err)
+	}
+	return nil
+}
+
+// 已存在的最大作者ID
+func maxAuthorID() (int, error) {
+	entries, err := os.ReadDir(authorDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read author directory: %w", err)
+	}
+	max := 0
+	for _, e := range entries {
+		if id, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".json")); err == nil && id > max {
+			max = id
+		}
+	}
+	return max, nil
+}
+
+// csvUser CSV中的一行
+type csvUser struct {
+	Row         int
+	Email       string
+	DisplayName string
+	Role        string
+	AuthorID    int
+	Timezone    string
+}
+
+// userImportError 某行某字段的错误，Row 为CSV中的行号（表头为第1行）
+type userImportError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+// userImportRow 某行的导入结果
+type userImportRow struct {
+	Row         int    `json:"row"`
+	Email       string `json:"email"`
+	Status      string `json:"status"` // created / updated / unchanged
+	UserID      int    `json:"user_id"`
+	AuthorID    int    `json:"author_id,omitempty"`
+	Invited     bool   `json:"invited,omitempty"`
+	InviteError string `json:"invite_error,omitempty"`
+}
+
+// userImportResult 导入报告；有错误时不做任何修改
+type userImportResult struct {
+	DryRun    bool              `json:"dry_run"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Unchanged int               `json:"unchanged"`
+	Invited   int               `json:"invited"`
+	Rows      []userImportRow   `json:"rows"`
+	Errors    []userImportError `json:"errors,omitempty"`
+}
+
+// CSV列名及别名
+var userCSVColumns = map[string]string{
+	"email":        "email",
+	"display_name": "display_name",
+	"name":         "display_name",
+	"role":         "role",
+	"author_id":    "author_id",
+	"timezone":     "timezone",
+}
+
+// 解析并校验CSV，返回全部行和逐行的错误
+func parseUserCSV(data []byte) ([]csvUser, []userImportError) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, []userImportError{{Error: "invalid CSV: " + err.Error()}}
+	}
+	if len(records) < 2 {
+		return nil, []userImportError{{Row: 1, Error: "CSV must have a header row and at least one user"}}
+	}
+
+	var errs []userImportError
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		field, ok := userCSVColumns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			errs = append(errs, userImportError{Row: 1, Field: name, Error: "unknown column"})
+			continue
+		}
+		columns[field] = i
+	}
+	for _, required := range []string{"email", "display_name", "role"} {
+		if _, ok := columns[required]; !ok {
+			errs = append(errs, userImportError{Row: 1, Field: required, Error: "missing required column"})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	var users []csvUser
+	emails := make(map[string]int)
+	authorIDs := make(map[int]int)
+	for i, record := range records[1:] {
+		row := i + 2
+		get := func(field string) string {
+			if col, ok := columns[field]; ok && col < len(record) {
+				return strings.TrimSpace(record[col])
+			}
+			return ""
+		}
+		fail := func(field, msg string) {
+			errs = append(errs, userImportError{Row: row, Field: field, Error: msg})
+		}
+
+		u := csvUser{
+			Row:         row,
+			Email:       strings.ToLower(get("email")),
+			DisplayName: get("display_name"),
+			Role:        strings.ToLower(get("role")),
+			Timezone:    get("timezone"),
+		}
+		if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+			fail("email", "invalid email address")
+		} else if first, ok := emails[u.Email]; ok {
+			fail("email", fmt.Sprintf("duplicate of row %d", first))
+		} else {
+			emails[u.Email] = row
+		}
+		if u.DisplayName == "" {
+			fail("display_name", "required")
+		} else if utf8.RuneCountInString(u.DisplayName) > 100 {
+			fail("display_name", "longer than 100 characters")
+		}
+		if !userRoles[u.Role] {
+			fail("role", "must be one of admin, editor, author, viewer")
+		}
+		if v := get("author_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			switch {
+			case err != nil || id < 1:
+				fail("author_id", "must be a positive integer")
+			case u.Role == "viewer":
+				fail("author_id", "viewers cannot be linked to an author")
+			case authorIDs[id] != 0:
+				fail("author_id", fmt.Sprintf("already used by row %d", authorIDs[id]))
+			default:
+				u.AuthorID = id
+				authorIDs[id] = row
+			}
+		}
+		if u.Timezone != "" {
+			if _, err := time.LoadLocation(u.Timezone); err != nil {
+				fail("timezone", "unknown timezone")
+			}
+		}
+		users = append(users, u)
+	}
+	return users, errs
+}
+
+// 导入用户和作者资料：先校验全部行，任何一行有误则不做修改。
+// 按邮箱匹配已有用户，重复导入同一文件不会产生变化；invite 时向尚未接受邀请的用户发送邀请。
+func importUsers(data []byte, dryRun, invite bool) (*userImportResult, error) {
+	result := &userImportResult{DryRun: dryRun, Rows: []userImportRow{}}
+	rows, errs := parseUserCSV(data)
+	if len(errs) > 0 {
+		result.Errors = errs
+		return result, nil
+	}
+
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	users, err := loadUsers()
+	if err != nil {
+		return nil, err
+	}
+	byEmail := make(map[string]int)
+	authorOwner := make(map[int]string)
+	nextUserID := 1
+	for i, u := range users {
+		byEmail[u.Email] = i
+		if u.AuthorID != 0 {
+			authorOwner[u.AuthorID] = u.Email
+		}
+		if u.ID >= nextUserID {
+			nextUserID = u.ID + 1
+		}
+	}
+	nextAuthorID, err := maxAuthorID()
+	if err != nil {
+		return nil, err
+	}
+	for id := range authorOwner {
+		if id > nextAuthorID {
+			nextAuthorID = id
+		}
+	}
+
+	// 作者ID不能已关联到其他用户
+	for _, row := range rows {
+		if owner, ok := authorOwner[row.AuthorID]; ok && row.AuthorID != 0 && owner != row.Email {
+			result.Errors = append(result.Errors, userImportError{Row: row.Row, Field: "author_id", Error: "already linked to " + owner})
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	var authors []*Author
+	now := time.Now().UTC()
+	for _, row := range rows {
+		status := "unchanged"
+		i, exists := byEmail[row.Email]
+		if !exists {
+			users = append(users, User{ID: nextUserID, Email: row.Email, CreatedTime: now})
+			nextUserID++
+			i = len(users) - 1
+			byEmail[row.Email] = i
+			status = "created"
+		}
+		u := &users[i]
+		before := *u
+		u.DisplayName, u.Role = row.DisplayName, row.Role
+
+		if row.Role != "viewer" {
+			switch {
+			case row.AuthorID != 0:
+				u.AuthorID = row.AuthorID
+			case u.AuthorID == 0:
+				nextAuthorID++
+				u.AuthorID = nextAuthorID
+			}
+			author, err := LoadAuthor(u.AuthorID)
+			if err != nil {
+				author = &Author{ID: u.AuthorID}
+			}
+			if author.Name != u.DisplayName || row.Timezone != "" && author.Timezone != row.Timezone {
+				author.Name = u.DisplayName
+				if row.Timezone != "" {
+					author.Timezone = row.Timezone
+				}
+				authors = append(authors, author)
+				if status == "unchanged" {
+					status = "updated"
+				}
+			}
+		} else {
+			// 读者没有作者身份
+			u.AuthorID = 0
+		}
+		if status == "unchanged" && (before.DisplayName != u.DisplayName || before.Role != u.Role || before.AuthorID != u.AuthorID) {
+			status = "updated"
+		}
+
+		switch status {
+		case "created":
+			result.Created++
+		case "updated":
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+		result.Rows = append(result.Rows, userImportRow{Row: row.Row, Email: u.Email, Status: status, UserID: u.ID, AuthorID: u.AuthorID})
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	// 写入前检查邮件配置，避免只写入了部分数据
+	var mailer Mailer
+	if invite {
+		if mailer, err = newMailer(); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range authors {
+		if err := a.Save(); err != nil {
+			return nil, err
+		}
+	}
+	if err := saveUsers(users); err != nil {
+		return nil, err
+	}
+
+	// 用户保存后再发送邀请，邮件中的链接总是对应已存在的用户
+	if invite {
+		for r := range result.Rows {
+			row := &result.Rows[r]
+			u := &users[byEmail[row.Email]]
+			if !needsInvitation(u, now) {
+				continue
+			}
+			if err := sendInvitation(mailer, u); err != nil {
+				log.Printf("Failed to invite %s: %v", u.Email, err)
+				row.InviteError = err.Error()
+				continue
+			}
+			u.InvitedAt = &now
+			row.Invited = true
+			result.Invited++
+		}
+		if result.Invited > 0 {
+			if err := saveUsers(users); err != nil {
+				return nil, fmt.Errorf("invitations were sent but could not be recorded: %w", err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// 尚未接受邀请，且从未邀请过或上次邀请已过期
+func needsInvitation(u *User, now time.Time) bool {
+	if u.ActivatedAt != nil {
+		return false
+	}
+	return u.InvitedAt == nil || now.Sub(*u.InvitedAt) > time.Duration(config.Invitations.ExpiryHours)*time.Hour
+}
+
+// 生成邀请令牌并发送邀请邮件
+func sendInvitation(mailer Mailer, u *User) error {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	token := hex.EncodeToString(secret)
+	expires := time.Now().UTC().Add(time.Duration(config.Invitations.ExpiryHours) * time.Hour)
+
+	invites, err := loadInvitations()
+	if err != nil {
+		return err
+	}
+	// 同一用户只保留最新的邀请链接
+	now := time.Now().UTC()
+	for i := range invites {
+		if invites[i].UserID == u.ID && invites[i].UsedAt == nil && invites[i].SupersededAt == nil {
+			invites[i].SupersededAt = &now
+		}
+	}
+	invites = append(invites, invitation{Hash: hashAPIKey(token), UserID: u.ID, ExpiresAt: expires})
+	if err := saveInvitations(invites); err != nil {
+		return err
+	}
+
+	link := strings.TrimRight(config.BaseURL, "/") + "/invite?token=" + url.QueryEscape(token)
+	return mailer.Send(Mail{
+		To:      u.Email,
+		Subject: fmt.Sprintf("You have been invited to %s", config.SiteTitle),
+		Body: fmt.Sprintf("Hi %s,\n\nYou have been added to %s as %s.\nOpen the link below to accept the invitation and get your API key:\n\n%s\n\nThe link expires on %s.\n",
+			u.DisplayName, config.SiteTitle, u.Role, link, expires.Format("2006-01-02 15:04 MST")),
+	})
+}
+
+// 接受邀请：令牌只能使用一次，成功后激活用户并创建API密钥
+func acceptInvitation(token string) (*User, string, error) {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+
+	invites, err := loadInvitations()
+	if err != nil {
+		return nil, "", err
+	}
+	hash := hashAPIKey(token)
+	var inv *invitation
+	for i := range invites {
+		if invites[i].Hash == hash {
+			inv = &invites[i]
+		}
+	}
+	now := time.Now().UTC()
+	switch {
+	case inv == nil:
+		return nil, "", fmt.Errorf("invalid invitation")
+	case inv.UsedAt != nil:
+		return nil, "", fmt.Errorf("invitation has already been used")
+	case inv.SupersededAt != nil:
+		return nil, "", fmt.Errorf("invitation has been replaced by a newer one")
+	case now.After(inv.ExpiresAt):
+		return nil, "", fmt.Errorf("invitation has expired")
+	}
+
+	users, err := loadUsers()
+	if err != nil {
+		return nil, "", err
+	}
+	var user *User
+	for i := range users {
+		if users[i].ID == inv.UserID {
+			user = &users[i]
+		}
+	}
+	if user == nil {
+		return nil, "", fmt.Errorf("invalid invitation")
+	}
+
+	_, apiKey, err := createAPIKey(APIKey{Name: "invitation for " + user.Email, UserID: user.ID, Admin: user.Role == "admin"})
+	if err != nil {
+		return nil, "", err
+	}
+	inv.UsedAt = &now
+	user.ActivatedAt = &now
+	if err := saveInvitations(invites); err != nil {
+		return nil, "", err
+	}
+	if err := saveUsers(users); err != nil {
+		return nil, "", err
+	}
+	return user, apiKey, nil
+}
+
+// 用户列表：GET /api/admin/users（需要管理员密钥）
+func usersHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	users, err := loadUsers()
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to load users", http.StatusInternalServerError)
+		return
+	}
+	sendResponse(w, true, "Users retrieved successfully", users, "", http.StatusOK)
+}
+
+// 批量导入：POST /api/admin/users/import?dry_run=1&invite=1，请求体为CSV
+// （列：email, display_name, role, author_id, timezone）
+func importUsersHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		sendResponse(w, false, "", nil, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	dryRun, _ := strconv.ParseBool(query.Get("dry_run"))
+	invite, _ := strconv.ParseBool(query.Get("invite"))
+
+	result, err := importUsers(body, dryRun, invite)
+	if err != nil {
+		log.Printf("Failed to import users: %v", err)
+		sendResponse(w, false, "", nil, "Failed to import users", http.StatusInternalServerError)
+		return
+	}
+	if len(result.Errors) > 0 {
+		sendResponse(w, false, "", result, fmt.Sprintf("%d error(s) found; no users were imported", len(result.Errors)), http.StatusUnprocessableEntity)
+		return
+	}
+	sendResponse(w, true, "Users imported successfully", result, "", http.StatusOK)
+}
+
+// 接受邀请（JSON）：POST /api/invitations/accept {"token": "..."}
+func acceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendResponse(w, false, "", nil, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	user, apiKey, err := acceptInvitation(req.Token)
+	if err != nil {
+		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sendResponse(w, true, "Invitation accepted", map[string]interface{}{"user": user, "api_key": apiKey}, "", http.StatusOK)
+}
+
+var invitePage = template.Must(template.New("invite").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head><meta charset="utf-8"><title>{{.SiteTitle}}</title></head>
+<body><main>
+{{if .Error}}<p>{{.Error}}</p>
+{{else if .APIKey}}<p>{{.User.DisplayName}}，欢迎加入。你的API密钥如下，仅显示这一次，请妥善保存：</p>
+<pre>{{.APIKey}}</pre>
+{{else}}<form method="post" action="/invite">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">接受邀请</button>
+</form>{{end}}
+</main></body>
+</html>
+`))
+
+// 邀请链接页面：GET 显示确认按钮（避免链接预取消耗令牌），POST 接受邀请并显示API密钥
+func invitePageHandler(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		SiteTitle string
+		Token     string
+		User      *User
+		APIKey    string
+		Error     string
+	}{SiteTitle: config.SiteTitle}
+
+	switch r.Method {
+	case http.MethodGet:
+		data.Token = r.URL.Query().Get("token")
+	case http.MethodPost:
+		user, apiKey, err := acceptInvitation(r.FormValue("token"))
+		if err != nil {
+			data.Error = err.Error()
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		data.User, data.APIKey = user, apiKey
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if err := invitePage.Execute(w, data); err != nil {
+		log.Printf("Failed to render invite page: %v", err)
+	}
+}
+
+// 管理用户：blog users import [-dry-run] [-invite] <file.csv>，blog users list
+func runUsers(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: blog users import|list")
+	}
+	switch args[0] {
+	case "import":
+		fs := flag.NewFlagSet("users import", flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "validate and report without saving")
+		invite := fs.Bool("invite", false, "send invitation links to users who have not accepted one")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: blog users import [-dry-run] [-invite] <file.csv>")
+		}
+		data, err := os.ReadFile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		result, err := importUsers(data, *dryRun, *invite)
+		if err != nil {
+			return err
+		}
+		for _, e := range result.Errors {
+			fmt.Printf("row %d: %s: %s\n", e.Row, e.Field, e.Error)
+		}
+		if len(result.Errors) > 0 {
+			return fmt.Errorf("%d error(s) found; no users were imported", len(result.Errors))
+		}
+		for _, row := range result.Rows {
+			note := ""
+			if row.Invited {
+				note = " (invited)"
+			} else if row.InviteError != "" {
+				note = " (invitation failed: " + row.InviteError + ")"
+			}
+			fmt.Printf("row %d: %-9s user=%d author=%d %s%s\n", row.Row, row.Status, row.UserID, row.AuthorID, row.Email, note)
+		}
+		verb := "Imported"
+		if *dryRun {
+			verb = "Would import"
+		}
+		fmt.Printf("%s: %d created, %d updated, %d unchanged, %d invited\n", verb, result.Created, result.Updated, result.Unchanged, result.Invited)
+		return nil
+
+	case "list":
+		users, err := loadUsers()
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			state := "pending"
+			if u.ActivatedAt != nil {
+				state = "active"
+			}
+			fmt.Printf("%4d  %-30s %-20s %-7s author=%d %s\n", u.ID, u.Email, u.DisplayName, u.Role, u.AuthorID, state)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown users command %q", args[0])
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestImportUsersWritesNothingWhenMailerFails(t *testing.T) {
+	useTestSite(t)
+	prevDriver := config.Mail.Driver
+	t.Cleanup(func() { config.Mail.Driver = prevDriver })
+	config.Mail.Driver = "bogus"
+
+	csv := "email,display_name,role\nann@example.com,Ann,author\n"
+	if _, err := importUsers([]byte(csv), false, true); err == nil {
+		t.Fatal("import succeeded without a working mailer")
+	}
+	if entries, _ := os.ReadDir(authorDir); len(entries) != 0 {
+		t.Errorf("%d author(s) written", len(entries))
+	}
+	if users, _ := loadUsers(); len(users) != 0 {
+		t.Errorf("%d user(s) written", len(users))
+	}
+}
+
+func TestAdminKeyFollowsUserRole(t *testing.T) {
+	useTestSite(t)
+	if _, err := importUsers([]byte("email,display_name,role\nann@example.com,Ann,admin\n"), false, false); err != nil {
+		t.Fatal(err)
+	}
+	users, _ := loadUsers()
+	key, _, err := createAPIKey(APIKey{Name: "test", UserID: users[0].ID, Admin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	isAdmin := func() bool {
+		r := withAPIKey(httptest.NewRequest(http.MethodGet, "/api/admin/users", nil), key)
+		return requireAdmin(httptest.NewRecorder(), r)
+	}
+	if !isAdmin() {
+		t.Fatal("admin user's key was rejected")
+	}
+
+	// 重新导入时降级为读者，已有的密钥随即失去管理权限
+	if _, err := importUsers([]byte("email,display_name,role\nann@example.com,Ann,viewer\n"), false, false); err != nil {
+		t.Fatal(err)
+	}
+	if isAdmin() {
+		t.Error("demoted user's key still has admin access")
+	}
+}
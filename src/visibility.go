@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// visibilityPrincipal 被诊断的访问者：匿名、某个API密钥或某个用户
+type visibilityPrincipal struct {
+	Kind     string `json:"kind"`                // anonymous、key 或 user
+	KeyID    string `json:"key_id,omitempty"`    // 使用的密钥
+	UserID   int    `json:"user_id,omitempty"`   // 用户
+	Role     string `json:"role,omitempty"`      // 用户角色
+	AuthorID int    `json:"author_id,omitempty"` // 用户对应的作者
+	Admin    bool   `json:"admin,omitempty"`     // 是否为管理员密钥
+
+	key *APIKey
+}
+
+// visibilityCheck 单条规则的判定
+type visibilityCheck struct {
+	Rule   string `json:"rule"`
+	Result string `json:"result"` // allow、deny 或 skip（规则不适用于该入口）
+	Reason string `json:"reason"`
+}
+
+// visibilitySurface 某个访问入口的判定结果
+type visibilitySurface struct {
+	Surface   string            `json:"surface"`              // 入口，见 visibilitySurfaces
+	Routes    []string          `json:"routes"`               // 对应的路由
+	Visible   bool              `json:"visible"`              // 该访问者能否在此入口看到博客
+	DecidedBy string            `json:"decided_by,omitempty"` // 拒绝访问的第一条规则
+	Checks    []visibilityCheck `json:"checks"`               // 按顺序评估的全部规则
+}
+
+// visibilityReport 可见性诊断结果
+type visibilityReport struct {
+	BlogID    BlogID              `json:"blog_id"`
+	Title     string              `json:"title,omitempty"`
+	Principal visibilityPrincipal `json:"principal"`
+	Surfaces  []visibilitySurface `json:"surfaces"`
+}
+
+// 访问入口及其路由
+var visibilitySurfaces = []struct {
+	name   string
+	routes []string
+}{
+	{"api", []string{"GET /api/blogs/{id}"}},
+	{"page", []string{"GET /blogs/{id}", "static release /blogs/{id}/"}},
+	{"listings", []string{"GET /", "GET /tags/{tag}", "GET /archive/", "GET /feed.xml", "/sitemap.xml"}},
+	{"search", []string{"GET /api/search"}},
+}
+
+// visibilityRule 可见性规则：返回 allow、deny 或 skip 以及原因
+type visibilityRule struct {
+	name  string
+	check func(surface string, blog *Blog, p *visibilityPrincipal) (string, string)
+}
+
+// 可见性规则，按各入口实际执行的顺序排列；第一条 deny 决定结果
+var visibilityRules = []visibilityRule{
+	{"exists", func(surface string, blog *Blog, p *visibilityPrincipal) (string, string) {
+		if blog == nil {
+			return "deny", "the blog does not exist or its file cannot be read"
+		}
+		return "allow", "the blog exists"
+	}},
+	{"api_key", func(surface string, blog *Blog, p *visibilityPrincipal) (string, string) {
+		if surface != "api" && surface != "search" {
+			return "skip", "HTML pages and feeds do not read API keys"
+		}
+		switch p.Kind {
+		case "anonymous":
+			return "allow", "anonymous requests are accepted"
+		case "user":
+			if p.key == nil {
+				return "allow", "the user has no API key (invitation not accepted?), so their requests are anonymous"
+			}
+		}
+		return "allow", fmt.Sprintf("key %s is valid", p.key.ID)
+	}},
+	{"rate_limit", func(surface string, blog *Blog, p *visibilityPrincipal) (string, string) {
+		if surface != "api" && surface != "search" {
+			return "skip", "only /api/ routes are rate-limited"
+		}
+		if p.key == nil {
+			if rate := config.Usage.AnonymousRatePerMinute; rate > 0 {
+				return "allow", fmt.Sprintf("anonymous requests are limited to %d per minute per IP address; requests over the limit fail with 429", rate)
+			}
+			return "allow", "no rate limit is configured for anonymous requests"
+		}
+		rate := config.Usage.RatePerMinute
+		if p.key.RatePerMinute > 0 {
+			rate = p.key.RatePerMinute
+		}
+		if rate <= 0 {
+			return "allow", "no rate limit is configured for this key"
+		}
+		if limiter.exhausted("key:"+p.key.ID, rate, time.Now()) {
+			return "deny", fmt.Sprintf("key %s is currently throttled (limit %d requests per minute); requests fail with 429 until tokens refill", p.key.ID, rate)
+		}
+		return "allow", fmt.Sprintf("key %s is limited to %d requests per minute and is not currently throttled", p.key.ID, rate)
+	}},
+	{"daily_quota", func(surface string, blog *Blog, p *visibilityPrincipal) (string, string) {
+		if surface != "api" && surface != "search" {
+			return "skip", "only /api/ routes count against quotas"
+		}
+		if p.key == nil {
+			return "skip", "anonymous requests have no daily quota (only a per-minute rate limit)"
+		}
+		quota := config.Usage.DailyQuota
+		if p.key.DailyQuota > 0 {
+			quota = p.key.DailyQuota
+		}
+		if quota <= 0 {
+			return "allow", "no daily quota is configured for this key"
+		}
+		used := usage.today(p.key.ID)
+		if used >= int64(quota) {
+			return "deny", fmt.Sprintf("key %s has used its daily quota (%d of %d requests); requests fail with 429 until midnight UTC", p.key.ID, used, quota)
+		}
+		return "allow", fmt.Sprintf("%d of %d requests used today", used, quota)
+	}},
+	{"published", func(surface string, blog *Blog, p *visibilityPrincipal) (string, string) {
+		if surface == "api" {
+			switch {
+			case blog.IsPublished:
+				return "allow", "the blog is published"
+			case p.key == nil:
+				return "deny", "the blog is a draft; the blog API returns 404 for drafts to requests without an API key"
+			}
+			return "allow", "the blog is a draft, but requests with an API key can read drafts"
+		}
+		if !blog.IsPublished {
+			return "deny", "the blog is a draft (is_published is false); only published blogs are shown here, regardless of who asks"
+		}
+		return "allow", "the blog is published"
+	}},
+}
+
+// 解析被诊断的访问者：?key=<密钥ID> 或 ?user=<用户ID>，都没有时为匿名
+func resolveVisibilityPrincipal(r *http.Request) (*visibilityPrincipal, error) {
+	query := r.URL.Query()
+	p := &visibilityPrincipal{Kind: "anonymous"}
+	keyID, userParam := query.Get("key"), query.Get("user")
+	if keyID == "" && userParam == "" {
+		return p, nil
+	}
+	keys, err := loadAPIKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if keyID != "" {
+		for i := range keys {
+			if keys[i].ID == keyID {
+				p.Kind, p.key = "key", &keys[i]
+			}
+		}
+		if p.key == nil {
+			return nil, fmt.Errorf("API key %q not found", keyID)
+		}
+		if userParam == "" {
+			userParam = strconv.Itoa(p.key.UserID)
+		}
+	}
+
+	userID, err := strconv.Atoi(userParam)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if userID != 0 {
+		users, err := loadUsers()
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, u := range users {
+			if u.ID == userID {
+				found = true
+				p.UserID, p.Role, p.AuthorID = u.ID, u.Role, u.AuthorID
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("user %d not found", userID)
+		}
+		if p.key == nil {
+			p.Kind = "user"
+			// 用户最近创建的密钥
+			for i := range keys {
+				if keys[i].UserID == userID && (p.key == nil || keys[i].CreatedTime.After(p.key.CreatedTime)) {
+					p.key = &keys[i]
+				}
+			}
+		}
+	}
+	if p.key != nil {
+		p.KeyID, p.Admin = p.key.ID, keyIsAdmin(p.key)
+	}
+	return p, nil
+}
+
+// 对每个入口依次评估全部规则
+func diagnoseVisibility(id BlogID, p *visibilityPrincipal) visibilityReport {
+	blog, err := LoadBlog(id)
+	if err != nil {
+		blog = nil
+	}
+	report := visibilityReport{BlogID: id, Principal: *p, Surfaces: []visibilitySurface{}}
+	if blog != nil {
+		report.Title = blog.Title
+	}
+	for _, s := range visibilitySurfaces {
+		surface := visibilitySurface{Surface: s.name, Routes: s.routes, Visible: true, Checks: []visibilityCheck{}}
+		for _, rule := range visibilityRules {
+			check := visibilityCheck{Rule: rule.name, Result: "skip", Reason: "not evaluated because an earlier rule denied access"}
+			if surface.Visible {
+				check.Result, check.Reason = rule.check(s.name, blog, p)
+			}
+			if check.Result == "deny" {
+				surface.Visible, surface.DecidedBy = false, rule.name
+			}
+			surface.Checks = append(surface.Checks, check)
+		}
+		report.Surfaces = append(report.Surfaces, surface)
+	}
+	return report
+}
+
+var visibilityPath = regexp.MustCompile("^/api/admin/visibility/([0-9A-Za-z_-]+)$")
+
+// 可见性诊断：GET /api/admin/visibility/{id}?key=<密钥ID>&user=<用户ID>（需要管理员密钥）
+func visibilityHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	matches := visibilityPath.FindStringSubmatch(r.URL.Path)
+	if matches == nil {
+		sendResponse(w, false, "", nil, "invalid blog ID path", http.StatusBadRequest)
+		return
+	}
+	id, err := parseBlogID(matches[1])
+	if err != nil {
+		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p, err := resolveVisibilityPrincipal(r)
+	if err != nil {
+		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sendResponse(w, true, "Visibility evaluated", diagnoseVisibility(id, p), "", http.StatusOK)
+}
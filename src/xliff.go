@@ -0,0 +1,591 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// translationState 译文的分段状态，用于在原文修改后追踪需要重新翻译的段落
+type translationState struct {
+	SourceVersion int                  `json:"source_version"` // 最近一次同步时原文的版本号
+	Segments      []translationSegment `json:"segments"`
+	Outdated      bool                 `json:"outdated,omitempty"` // 已发布的译文在原文修改后尚未重新译完，仍显示上次完整的译文
+}
+
+// translationSegment 可翻译的一段内容
+type translationSegment struct {
+	Key        string `json:"key"`              // 段落标识（类型 + 原文摘要），即XLIFF中的unit id
+	Kind       string `json:"kind"`             // title / content / tag
+	SourceHash string `json:"source_hash"`      // 原文摘要
+	Source     string `json:"source"`           // 原文（Markdown）
+	Target     string `json:"target,omitempty"` // 译文
+	State      string `json:"state"`            // new 待翻译 / translated 已翻译 / changed 原文已修改 / locked 无需翻译（如代码块）
+}
+
+const xliffNS = "urn:oasis:names:tc:xliff:document:2.0"
+
+// XLIFF 2.0 文档结构（仅包含本站使用的部分）
+type xliffDoc struct {
+	XMLName xml.Name    `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string      `xml:"version,attr"`
+	SrcLang string      `xml:"srcLang,attr"`
+	TrgLang string      `xml:"trgLang,attr,omitempty"`
+	Files   []xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	ID       string      `xml:"id,attr"`
+	Original string      `xml:"original,attr,omitempty"`
+	Units    []xliffUnit `xml:"unit"`
+}
+
+type xliffUnit struct {
+	ID           string             `xml:"id,attr"`
+	Name         string             `xml:"name,attr,omitempty"`
+	OriginalData *xliffOriginalData `xml:"originalData,omitempty"`
+	Segments     []xliffSegment     `xml:"segment"`
+}
+
+type xliffOriginalData struct {
+	Data []xliffData `xml:"data"`
+}
+
+type xliffData struct {
+	ID    string `xml:"id,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xliffSegment struct {
+	State  string     `xml:"state,attr,omitempty"`
+	Source xliffText  `xml:"source"`
+	Target *xliffText `xml:"target,omitempty"`
+}
+
+// xliffText 含内联标签的文本，以原始XML保存
+type xliffText struct {
+	Inner string `xml:",innerxml"`
+}
+
+// 需要作为内联标签保护、不交给译者修改的Markdown语法
+var markdownSyntax = regexp.MustCompile("(?m)`[^`\n]*`|\\[@[^\\]\n]*\\]|!\\[[^\\]\n]*\\]\\([^)\n]*\\)|\\]\\([^)\n]*\\)|\\[|\\*\\*|__|\\*|\n|^(?:#{1,6} |> \\[![A-Za-z]+\\]\\s*|> |[-*+] |\\d+\\. )")
+
+// 将Markdown中的语法标记替换为 <ph/> 内联标签，返回内联XML和标签对应的原始内容。
+// 译文沿用原文的 data：相同的语法标记引用同一条原始内容，原文中没有的才追加。
+func protectMarkdown(text string, data []xliffData) (string, []xliffData) {
+	var sb strings.Builder
+	used := make(map[int]bool)
+	last := 0
+	for _, loc := range markdownSyntax.FindAllStringIndex(text, -1) {
+		xml.EscapeText(&sb, []byte(text[last:loc[0]]))
+		value := text[loc[0]:loc[1]]
+		n := -1
+		for i, d := range data {
+			if !used[i] && d.Value == value {
+				n = i
+				break
+			}
+		}
+		if n < 0 {
+			n = len(data)
+			data = append(data, xliffData{ID: "d" + strconv.Itoa(n+1), Value: value})
+		}
+		used[n] = true
+		fmt.Fprintf(&sb, `<ph id="%d" dataRef="%s"/>`, n+1, data[n].ID)
+		last = loc[1]
+	}
+	xml.EscapeText(&sb, []byte(text[last:]))
+	return sb.String(), data
+}
+
+// 将含 <ph/> 标签的内联XML还原为Markdown
+func restoreMarkdown(inner string, data []xliffData) (string, error) {
+	values := make(map[string]string)
+	for _, d := range data {
+		values[d.ID] = d.Value
+	}
+	dec := xml.NewDecoder(strings.NewReader("<t>" + inner + "</t>"))
+	var sb strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid inline content: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			sb.Write(t)
+		case xml.StartElement:
+			if t.Name.Local == "t" {
+				continue
+			}
+			if t.Name.Local != "ph" {
+				return "", fmt.Errorf("unsupported inline element <%s>", t.Name.Local)
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "dataRef" {
+					value, ok := values[attr.Value]
+					if !ok {
+						return "", fmt.Errorf("unknown dataRef %q", attr.Value)
+					}
+					sb.WriteString(value)
+				}
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// 将Markdown正文按空行切分为段落，围栏代码块保持完整
+func splitMarkdownSegments(content string) []string {
+	var segments []string
+	var current []string
+	inFence := false
+	flush := func() {
+		if len(current) > 0 {
+			segments = append(segments, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if !inFence {
+				flush()
+			}
+			inFence = !inFence
+			current = append(current, line)
+			if !inFence {
+				flush()
+			}
+			continue
+		}
+		if !inFence && strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return segments
+}
+
+// 原文的可翻译分段：标题、正文段落、标签
+func sourceSegments(b *Blog) []translationSegment {
+	var segs []translationSegment
+	seen := make(map[string]int)
+	add := func(kind, text string) {
+		sum := sha256.Sum256([]byte(text))
+		hash := hex.EncodeToString(sum[:])
+		key := kind + "-" + hash[:12]
+		// 相同内容的段落按出现次序区分
+		if seen[key]++; seen[key] > 1 {
+			key += "-" + strconv.Itoa(seen[key])
+		}
+		seg := translationSegment{Key: key, Kind: kind, SourceHash: hash, Source: text}
+		// 代码块和只有语法标记的段落（如图片、分隔线）无需翻译
+		if strings.HasPrefix(text, "```") || strings.TrimSpace(markdownSyntax.ReplaceAllString(text, "")) == "" {
+			seg.State = "locked"
+		}
+		segs = append(segs, seg)
+	}
+	add("title", b.Title)
+	for _, text := range splitMarkdownSegments(b.Content) {
+		add("content", text)
+	}
+	for _, tag := range b.Tags {
+		add("tag", tag)
+	}
+	return segs
+}
+
+// 按原文当前内容同步译文：未变化的段落保留译文，修改过的段落标记为 changed，新增段落标记为 new。
+// imported 为本次导入的译文（unit id -> 译文）。
+func syncTranslation(source, draft *Blog, imported map[string]string) {
+	var old []translationSegment
+	if draft.Translation != nil {
+		old = draft.Translation.Segments
+	}
+	oldByKey := make(map[string]translationSegment)
+	oldByPos := make(map[string][]translationSegment)
+	for _, s := range old {
+		oldByKey[s.Key] = s
+		oldByPos[s.Kind] = append(oldByPos[s.Kind], s)
+	}
+
+	segs := sourceSegments(source)
+	pos := make(map[string]int)
+	for i := range segs {
+		s := &segs[i]
+		p := pos[s.Kind]
+		pos[s.Kind]++
+		if s.State == "locked" {
+			s.Target = s.Source
+			continue
+		}
+		// 原样返回的旧译文（导出时附带的参考译文）不视为已翻译
+		if target := imported[s.Key]; target != "" && !(oldByKey[s.Key].State == "changed" && oldByKey[s.Key].Target == target) {
+			s.Target, s.State = target, "translated"
+			continue
+		}
+		if o, ok := oldByKey[s.Key]; ok && o.Target != "" {
+			s.Target, s.State = o.Target, o.State
+			continue
+		}
+		// 同一位置原先有译文但原文已改变，保留旧译文供译者参考
+		if p < len(oldByPos[s.Kind]) && oldByPos[s.Kind][p].Target != "" && oldByPos[s.Kind][p].State != "locked" {
+			s.Target, s.State = oldByPos[s.Kind][p].Target, "changed"
+			continue
+		}
+		s.State = "new"
+	}
+
+	draft.AuthorID = source.AuthorID
+	draft.TranslationOf = source.ID
+	draft.Translation = &translationState{SourceVersion: source.Version, Segments: segs}
+
+	// 已发布的译文不混入原文语言的段落：未全部译完时保留当前内容，标记为过期
+	if draft.IsPublished {
+		for _, s := range segs {
+			if s.State != "translated" && s.State != "locked" {
+				draft.Translation.Outdated = true
+				return
+			}
+		}
+	}
+
+	// 未翻译或原文已修改的段落暂用原文
+	text := func(s translationSegment) string {
+		if s.State == "translated" || s.State == "locked" {
+			return s.Target
+		}
+		return s.Source
+	}
+	var content []string
+	draft.Tags = nil
+	for _, s := range segs {
+		switch s.Kind {
+		case "title":
+			draft.Title = text(s)
+		case "content":
+			content = append(content, text(s))
+		case "tag":
+			draft.Tags = append(draft.Tags, text(s))
+		}
+	}
+	draft.Content = strings.Join(content, "\n\n")
+	draft.Blocks = nil
+}
+
+// 博客的语言，未设置时为站点语言
+func blogLang(b *Blog) string {
+	if b.Lang != "" {
+		return b.Lang
+	}
+	return config.Language
+}
+
+// 查找原文在某语言下的译文草稿
+func findTranslation(id BlogID, lang string) (*Blog, error) {
+	blogs, err := ListBlogs()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blogs {
+		if b.TranslationOf == id && strings.EqualFold(b.Lang, lang) {
+			return b, nil
+		}
+	}
+	return nil, nil
+}
+
+// 导出XLIFF：已有译文时附带现有译文和状态
+func exportXLIFF(source *Blog, trgLang string) ([]byte, error) {
+	segs := sourceSegments(source)
+	draft, err := findTranslation(source.ID, trgLang)
+	if err != nil {
+		return nil, err
+	}
+	if draft != nil {
+		view := *draft
+		syncTranslation(source, &view, nil)
+		segs = view.Translation.Segments
+	}
+
+	file := xliffFile{ID: "blog-" + string(source.ID), Original: fmt.Sprintf("blog:%s:v%d", source.ID, source.Version)}
+	for _, s := range segs {
+		if s.State == "locked" {
+			continue
+		}
+		inner, data := protectMarkdown(s.Source, nil)
+		unit := xliffUnit{ID: s.Key, Name: s.Kind}
+		seg := xliffSegment{State: "initial", Source: xliffText{inner}}
+		if s.Target != "" {
+			var target string
+			target, data = protectMarkdown(s.Target, data)
+			if s.State == "translated" {
+				seg.State = "translated"
+			}
+			seg.Target = &xliffText{target}
+		}
+		if len(data) > 0 {
+			unit.OriginalData = &xliffOriginalData{Data: data}
+		}
+		unit.Segments = []xliffSegment{seg}
+		file.Units = append(file.Units, unit)
+	}
+
+	doc := xliffDoc{Version: "2.0", SrcLang: blogLang(source), TrgLang: trgLang, Files: []xliffFile{file}}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal XLIFF: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+var xliffFileID = regexp.MustCompile(`^blog-([0-9A-Za-z_-]+)$`)
+
+// 导入译者返回的XLIFF，创建或更新关联的译文草稿；返回草稿和无法对应到当前原文的段落数。
+// expected 不为空时，文件必须属于该博客，否则不保存任何内容
+func importXLIFF(data []byte, expected BlogID) (*Blog, int, error) {
+	var doc xliffDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("invalid XLIFF: %w", err)
+	}
+	if doc.Version != "2.0" {
+		return nil, 0, fmt.Errorf("unsupported XLIFF version %q", doc.Version)
+	}
+	if doc.TrgLang == "" {
+		return nil, 0, fmt.Errorf("XLIFF has no trgLang")
+	}
+	if len(doc.Files) != 1 {
+		return nil, 0, fmt.Errorf("expected exactly one <file>, got %d", len(doc.Files))
+	}
+	m := xliffFileID.FindStringSubmatch(doc.Files[0].ID)
+	if m == nil {
+		return nil, 0, fmt.Errorf("unrecognized file id %q", doc.Files[0].ID)
+	}
+	if expected != "" && BlogID(m[1]) != expected {
+		return nil, 0, fmt.Errorf("XLIFF file belongs to another blog")
+	}
+	source, err := LoadBlog(BlogID(m[1]))
+	if err != nil {
+		return nil, 0, fmt.Errorf("source blog %s not found", m[1])
+	}
+	if strings.EqualFold(doc.TrgLang, blogLang(source)) {
+		return nil, 0, fmt.Errorf("target language is the same as the source language")
+	}
+
+	current := make(map[string]bool)
+	for _, s := range sourceSegments(source) {
+		current[s.Key] = true
+	}
+	imported := make(map[string]string)
+	outdated := 0
+	for _, unit := range doc.Files[0].Units {
+		if len(unit.Segments) == 0 || unit.Segments[0].Target == nil {
+			continue
+		}
+		var originalData []xliffData
+		if unit.OriginalData != nil {
+			originalData = unit.OriginalData.Data
+		}
+		var parts []string
+		for _, seg := range unit.Segments {
+			if seg.Target == nil {
+				continue
+			}
+			text, err := restoreMarkdown(seg.Target.Inner, originalData)
+			if err != nil {
+				return nil, 0, fmt.Errorf("unit %s: %w", unit.ID, err)
+			}
+			parts = append(parts, text)
+		}
+		if !current[unit.ID] {
+			// 导出后原文又被修改过，这段译文对应的原文已不存在
+			outdated++
+			continue
+		}
+		if text := strings.Join(parts, ""); strings.TrimSpace(text) != "" {
+			imported[unit.ID] = text
+		}
+	}
+
+	draft, err := findTranslation(source.ID, doc.TrgLang)
+	if err != nil {
+		return nil, 0, err
+	}
+	if draft == nil {
+		draft = &Blog{ID: newBlogID(), Lang: doc.TrgLang}
+	}
+	syncTranslation(source, draft, imported)
+	if err := SaveBlog(draft); err != nil {
+		return nil, 0, err
+	}
+	return draft, outdated, nil
+}
+
+// 编辑译文时未提交翻译信息的，沿用原有的关联和分段状态
+func keepTranslationLink(old, blog *Blog) {
+	if old == nil || old.TranslationOf == "" || blog.TranslationOf != "" {
+		return
+	}
+	blog.TranslationOf, blog.Translation = old.TranslationOf, old.Translation
+	if blog.Lang == "" {
+		blog.Lang = old.Lang
+	}
+}
+
+// 原文保存后同步其全部译文的分段状态
+func updateTranslations(old, blog *Blog) {
+	if blog.TranslationOf != "" || old == nil || old.Title == blog.Title && old.Content == blog.Content && strings.Join(old.Tags, "\x00") == strings.Join(blog.Tags, "\x00") {
+		return
+	}
+	blogs, err := ListBlogs()
+	if err != nil {
+		log.Printf("Failed to list translations of %s: %v", blog.ID, err)
+		return
+	}
+	for _, b := range blogs {
+		if b.TranslationOf != blog.ID {
+			continue
+		}
+		syncTranslation(blog, b, nil)
+		if err := SaveBlog(b); err != nil {
+			log.Printf("Failed to update translation %s: %v", b.ID, err)
+		}
+	}
+}
+
+func init() {
+	onBlogSaved(updateTranslations)
+}
+
+var blogXLIFFPath = regexp.MustCompile("^/api/blogs/([0-9A-Za-z_-]+)/xliff$")
+
+// 翻译处理器：GET /api/blogs/{id}/xliff?lang=en 导出，POST 导入译文（都需要API密钥）
+func xliffHandler(w http.ResponseWriter, r *http.Request) {
+	if requestAPIKey(r) == nil {
+		sendResponse(w, false, "", nil, "API key required", http.StatusUnauthorized)
+		return
+	}
+	matches := blogXLIFFPath.FindStringSubmatch(r.URL.Path)
+	id, err := parseBlogID(matches[1])
+	if err != nil {
+		sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		source, err := LoadBlog(id)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Blog not found", http.StatusNotFound)
+			return
+		}
+		lang := r.URL.Query().Get("lang")
+		if lang == "" {
+			sendResponse(w, false, "", nil, "lang is required", http.StatusBadRequest)
+			return
+		}
+		data, err := exportXLIFF(source, lang)
+		if err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xliff+xml; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="blog-%s.%s.xlf"`, id, lang))
+		w.Write(data)
+
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			sendResponse(w, false, "", nil, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		draft, outdated, err := importXLIFF(body, id)
+		if err != nil {
+			sendResponse(w, false, "", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+		message := "Translation imported"
+		if outdated > 0 {
+			message = fmt.Sprintf("Translation imported; %d segment(s) skipped because the source changed", outdated)
+		}
+		if draft.Translation.Outdated {
+			message += "; the published text is kept until every segment is translated"
+		}
+		sendResponse(w, true, message, draft, "", http.StatusOK)
+
+	default:
+		sendResponse(w, false, "", nil, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// 命令行导出/导入：blog xliff export -lang en [-o file] <id>，blog xliff import <file>
+func runXLIFF(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: blog xliff export|import")
+	}
+	switch args[0] {
+	case "export":
+		fs := flag.NewFlagSet("xliff export", flag.ExitOnError)
+		lang := fs.String("lang", "", "target language, e.g. en")
+		out := fs.String("o", "", "output file (defaults to stdout)")
+		fs.Parse(args[1:])
+		if *lang == "" || fs.NArg() != 1 {
+			return fmt.Errorf("usage: blog xliff export -lang en [-o file] <id>")
+		}
+		id, err := parseBlogID(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		source, err := LoadBlog(id)
+		if err != nil {
+			return err
+		}
+		data, err := exportXLIFF(source, *lang)
+		if err != nil {
+			return err
+		}
+		if *out == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		return os.WriteFile(*out, data, 0644)
+
+	case "import":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: blog xliff import <file>")
+		}
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		draft, outdated, err := importXLIFF(data, "")
+		if err != nil {
+			return err
+		}
+		counts := make(map[string]int)
+		for _, s := range draft.Translation.Segments {
+			counts[s.State]++
+		}
+		fmt.Printf("Imported %s translation of blog %s as draft %s: %d translated, %d new, %d changed, %d outdated\n",
+			draft.Lang, draft.TranslationOf, draft.ID, counts["translated"], counts["new"], counts["changed"], outdated)
+		if draft.Translation.Outdated {
+			fmt.Println("The translation is published; its text is kept until every segment is translated")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown xliff command %q", args[0])
+}